@@ -0,0 +1,53 @@
+package apib_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+func Test_Do_should_encode_and_decode_json(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if r.Method != "PUT" || string(b) != `{"title":"a"}` || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %v %s %v, want PUT with JSON body", r.Method, b, r.Header)
+		}
+		w.Write([]byte(`{"title":"b"}`))
+	}))
+	defer srv.Close()
+
+	type note struct {
+		Title string `json:"title"`
+	}
+
+	var out note
+	err := apib.Do(context.Background(), nil, "PUT", srv.URL, note{"a"}, &out)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	if out.Title != "b" {
+		t.Errorf("out.Title = %q, want b", out.Title)
+	}
+}
+
+func Test_Do_should_return_status_errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	err := apib.Do(context.Background(), srv.Client(), "GET", srv.URL, nil, nil)
+	statusErr, ok := err.(*apib.StatusError)
+	if !ok {
+		t.Fatalf("err = %v, want *apib.StatusError", err)
+	}
+
+	if statusErr.StatusCode != http.StatusNotFound || string(statusErr.Body) != "gone\n" {
+		t.Errorf("err = %v %q, want 404 gone", statusErr.StatusCode, statusErr.Body)
+	}
+}
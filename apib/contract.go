@@ -0,0 +1,133 @@
+package apib
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+)
+
+// Reporter is the subset of testing.TB used to report contract violations.
+type Reporter interface {
+	Errorf(format string, args ...interface{})
+}
+
+// Exchange is a documented request and the response expected for it.
+type Exchange struct {
+	Name   string
+	Method string
+	URL    string
+	Header map[string]string
+	Body   string
+
+	Status int
+	// ResponseHeader are the headers the response must include, only the
+	// media type of Content-Type is compared.
+	ResponseHeader map[string]string
+	// Model returns a pointer to the value the response body must decode into
+	// without unknown fields, nil skips the check. JSON numbers also decode
+	// into strings, which hold the numbers of the generated models.
+	Model func() interface{}
+}
+
+// Verify sends the request of each exchange to h and reports responses with
+// a different status code, a missing header or a body that does not decode
+// into the model.
+func Verify(t Reporter, h http.Handler, exchanges []Exchange) {
+	for _, e := range exchanges {
+		req := httptest.NewRequest(e.Method, e.URL, strings.NewReader(e.Body))
+		for name, value := range e.Header {
+			req.Header.Set(name, value)
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		if w.Code != e.Status {
+			t.Errorf("%v: status = %v, want %v", e.Name, w.Code, e.Status)
+		}
+
+		names := make([]string, 0, len(e.ResponseHeader))
+		for name := range e.ResponseHeader {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			value := e.ResponseHeader[name]
+			actual := w.Header().Get(name)
+			switch {
+			case actual == "":
+				t.Errorf("%v: missing header %v", e.Name, name)
+			case strings.EqualFold(name, "Content-Type") && mediaType(actual) != mediaType(value):
+				t.Errorf("%v: Content-Type = %v, want %v", e.Name, actual, value)
+			}
+		}
+
+		if e.Model == nil {
+			continue
+		}
+
+		model := e.Model()
+		if err := decodeModel(w.Body.Bytes(), model); err != nil {
+			t.Errorf("%v: body does not decode into %T: %v", e.Name, model, err)
+		}
+	}
+}
+
+// decodeModel decodes b into model without unknown fields. When that fails
+// it is retried with the numbers of b quoted, the generated models hold
+// numbers as strings.
+func decodeModel(b []byte, model interface{}) error {
+	err := decodeStrict(b, model)
+	if err == nil {
+		return nil
+	}
+
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if dec.Decode(&v) != nil {
+		return err
+	}
+	quoted, qerr := json.Marshal(quoteNumbers(v))
+	if qerr != nil || decodeStrict(quoted, model) != nil {
+		return err
+	}
+	return nil
+}
+
+func decodeStrict(b []byte, model interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	return dec.Decode(model)
+}
+
+// quoteNumbers replaces the numbers of the decoded JSON value v by strings.
+func quoteNumbers(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		return t.String()
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = quoteNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = quoteNumbers(e)
+		}
+	}
+	return v
+}
+
+// mediaType returns the media type of a Content-Type without parameters.
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return mt
+}
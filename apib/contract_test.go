@@ -0,0 +1,108 @@
+package apib_test
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+type reporter []string
+
+func (r *reporter) Errorf(format string, args ...interface{}) {
+	*r = append(*r, fmt.Sprintf(format, args...))
+}
+
+func Test_Verify_should_report_contract_violations(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "bad media type", http.StatusUnsupportedMediaType)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1,"extra":true}`))
+	})
+
+	type note struct {
+		ID int `json:"id"`
+	}
+	type fullNote struct {
+		ID    int  `json:"id"`
+		Extra bool `json:"extra"`
+	}
+
+	exchanges := []apib.Exchange{
+		{
+			Name:           "ok",
+			Method:         "POST",
+			URL:            "/notes",
+			Header:         map[string]string{"Content-Type": "application/json"},
+			Status:         201,
+			ResponseHeader: map[string]string{"Content-Type": "application/json"},
+			Model:          func() interface{} { return new(fullNote) },
+		},
+		{
+			Name:           "violations",
+			Method:         "POST",
+			URL:            "/notes",
+			Header:         map[string]string{"Content-Type": "application/json"},
+			Status:         200,
+			ResponseHeader: map[string]string{"Location": "/notes/1", "Content-Type": "text/plain"},
+			Model:          func() interface{} { return new(note) },
+		},
+		{
+			Name:   "media type",
+			Method: "POST",
+			URL:    "/notes",
+			Status: 415,
+		},
+	}
+
+	var r reporter
+	apib.Verify(&r, h, exchanges)
+
+	expected := []string{
+		"violations: status = 201, want 200",
+		"violations: Content-Type = application/json; charset=utf-8, want text/plain",
+		"violations: missing header Location",
+		`violations: body does not decode into *apib_test.note: json: unknown field "extra"`,
+	}
+
+	if !reflect.DeepEqual([]string(r), expected) {
+		t.Errorf("errors = %q, want %q", r, expected)
+	}
+}
+
+func Test_Verify_should_decode_numbers_into_strings(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":12345678901234567890,"tags":[1.5],"name":"a"}`))
+	})
+
+	type note struct {
+		ID   *string  `json:"id"`
+		Tags []string `json:"tags"`
+		Name *string  `json:"name"`
+	}
+	type strictNote struct {
+		ID *string `json:"id"`
+	}
+
+	exchanges := []apib.Exchange{
+		{Name: "strings", Method: "GET", URL: "/notes/1", Status: 200, Model: func() interface{} { return new(note) }},
+		{Name: "unknown", Method: "GET", URL: "/notes/1", Status: 200, Model: func() interface{} { return new(strictNote) }},
+	}
+
+	var r reporter
+	apib.Verify(&r, h, exchanges)
+
+	expected := []string{
+		`unknown: body does not decode into *apib_test.strictNote: json: cannot unmarshal number into Go struct field strictNote.id of type string`,
+	}
+
+	if !reflect.DeepEqual([]string(r), expected) {
+		t.Errorf("errors = %q, want %q", r, expected)
+	}
+}
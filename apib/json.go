@@ -0,0 +1,62 @@
+package apib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Keys returns the set of member names in the JSON object b.
+func Keys(b []byte) (map[string]bool, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(members))
+	for k := range members {
+		keys[k] = true
+	}
+	return keys, nil
+}
+
+// MarshalObjects marshals each value to a JSON object and merges their
+// members into a single object. Nil values are skipped.
+func MarshalObjects(values ...interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	first := true
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if string(b) == "null" {
+			continue
+		}
+
+		if len(b) < 2 || b[0] != '{' || b[len(b)-1] != '}' {
+			return nil, fmt.Errorf("apib: %T is not a JSON object", v)
+		}
+
+		members := b[1 : len(b)-1]
+		if len(members) == 0 {
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		buf.Write(members)
+		first = false
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,52 @@
+package apib_test
+
+import (
+	"testing"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+func Test_MarshalObjects_should_merge_members(t *testing.T) {
+	type a struct {
+		A string `json:"a"`
+	}
+	type b struct {
+		B string `json:"b,omitempty"`
+	}
+
+	// values, expected
+	dataTable := [][]interface{}{
+		{[]interface{}{a{"1"}, b{"2"}}, `{"a":"1","b":"2"}`},
+		{[]interface{}{a{"1"}, nil, b{}}, `{"a":"1"}`},
+		{[]interface{}{nil}, `{}`},
+	}
+
+	for i, td := range dataTable {
+		actual, err := apib.MarshalObjects(td[0].([]interface{})...)
+		if err != nil {
+			t.Errorf("[%v] err = %v, want nil", i, err)
+		}
+
+		if string(actual) != td[1].(string) {
+			t.Errorf("[%v] MarshalObjects() = %s, want %s", i, actual, td[1])
+		}
+	}
+}
+
+func Test_MarshalObjects_should_reject_non_objects(t *testing.T) {
+	_, err := apib.MarshalObjects("text")
+	if err == nil {
+		t.Errorf("err = nil, want error")
+	}
+}
+
+func Test_Keys(t *testing.T) {
+	keys, err := apib.Keys([]byte(`{"iban":"x","bic":null}`))
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	if len(keys) != 2 || !keys["iban"] || !keys["bic"] {
+		t.Errorf("keys = %v, want iban and bic", keys)
+	}
+}
@@ -0,0 +1,191 @@
+package apib
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// HandlerFunc handles a request routed by a URI template, vars holds the
+// values of the template variables.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request, vars map[string]string)
+
+// Router dispatches requests to the handler registered for the method and the
+// first URI template that matches the path.
+type Router struct {
+	routes []*route
+}
+
+type route struct {
+	method  string
+	matcher *matcher
+	handler HandlerFunc
+}
+
+// Handle registers h for requests with method whose URL matches template.
+func (rt *Router) Handle(method, template string, h HandlerFunc) {
+	rt.routes = append(rt.routes, &route{method, compile(template), h})
+}
+
+// ServeHTTP responds with 404 when no template matches and 405 when the path
+// matches but the method does not.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var allow []string
+	for _, route := range rt.routes {
+		vars, ok := route.matcher.match(r.URL)
+		if !ok {
+			continue
+		}
+		if route.method != r.Method {
+			allow = append(allow, route.method)
+			continue
+		}
+		route.handler(w, r, vars)
+		return
+	}
+
+	if len(allow) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	sort.Strings(allow)
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+}
+
+// Decode decodes the JSON body of r into v, it responds with 400 and returns
+// false when the body is invalid.
+func Decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// Respond writes out as JSON with status. A *StatusError is written with its
+// status code and body, any other error as 500.
+func Respond(w http.ResponseWriter, status int, out interface{}, err error) {
+	if statusErr, ok := err.(*StatusError); ok {
+		w.WriteHeader(statusErr.StatusCode)
+		w.Write(statusErr.Body)
+		return
+	} else if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if out == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(out)
+}
+
+// Match reports whether u matches the URI template and returns the values of
+// its variables. Query expressions such as {?limit} are read from the query
+// string and do not need to be present.
+func Match(template string, u *url.URL) (map[string]string, bool) {
+	return compile(template).match(u)
+}
+
+// matcher matches the path of a URL with a regular expression compiled from
+// the path expressions of a URI template.
+type matcher struct {
+	re    *regexp.Regexp
+	path  []expression
+	query []varSpec
+}
+
+// patterns match the value of each path expression by operator prefix.
+var patterns = map[string]string{
+	"":  `([^/?#]*)`,
+	"/": `((?:/[^/?#]*)*)`,
+	".": `((?:\.[^/?#.]*)*)`,
+	";": `((?:;[^/?#]*)*)`,
+}
+
+func compile(template string) *matcher {
+	m := &matcher{}
+	literals, exprs := parseTemplate(template)
+
+	var re strings.Builder
+	re.WriteString("^")
+	inQuery := false
+	for i, literal := range literals {
+		if j := strings.IndexByte(literal, '?'); j >= 0 && !inQuery {
+			re.WriteString(regexp.QuoteMeta(literal[:j]))
+			inQuery = true
+		} else if !inQuery {
+			re.WriteString(regexp.QuoteMeta(literal))
+		}
+
+		if i == len(exprs) {
+			break
+		}
+
+		e := exprs[i]
+		switch {
+		case e.op.named && e.op.first != ";":
+			m.query = append(m.query, e.vars...)
+			inQuery = true
+		case e.op.first == "#" || inQuery:
+		case e.op.reserved:
+			re.WriteString(`([^?#]*)`)
+			m.path = append(m.path, e)
+		default:
+			re.WriteString(patterns[e.op.first])
+			m.path = append(m.path, e)
+		}
+	}
+	re.WriteString("$")
+
+	m.re = regexp.MustCompile(re.String())
+	return m
+}
+
+func (m *matcher) match(u *url.URL) (map[string]string, bool) {
+	groups := m.re.FindStringSubmatch(u.EscapedPath())
+	if groups == nil {
+		return nil, false
+	}
+
+	vars := map[string]string{}
+	for i, e := range m.path {
+		value := strings.TrimPrefix(groups[i+1], e.op.first)
+		var values []string
+		if value != "" {
+			values = strings.Split(value, e.op.sep)
+		}
+
+		for j, v := range e.vars {
+			if j >= len(values) {
+				break
+			}
+			s := values[j]
+			if e.op.named {
+				s = strings.TrimPrefix(s, v.name+"=")
+			}
+			if unescaped, err := url.PathUnescape(s); err == nil {
+				s = unescaped
+			}
+			vars[v.name] = s
+		}
+	}
+
+	query := u.Query()
+	for _, v := range m.query {
+		if value := query.Get(v.name); value != "" {
+			vars[v.name] = value
+		}
+	}
+
+	return vars, true
+}
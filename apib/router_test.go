@@ -0,0 +1,81 @@
+package apib_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+func Test_Match_should_extract_variables(t *testing.T) {
+	// template, url, matched, vars
+	dataTable := [][]interface{}{
+		{"/notes", "/notes", true, map[string]string{}},
+		{"/notes", "/notes/1", false, map[string]string(nil)},
+		{"/notes/{id}", "/notes/42", true, map[string]string{"id": "42"}},
+		{"/notes/{id}", "/notes/4%202", true, map[string]string{"id": "4 2"}},
+		{"/notes/{id}", "/notes/1/tags", false, map[string]string(nil)},
+		{"/files/{+path}", "/files/a/b", true, map[string]string{"path": "a/b"}},
+		{"/notes{?limit,q}", "/notes?limit=10", true, map[string]string{"limit": "10"}},
+		{"/notes?sort=id{&limit}", "/notes?sort=id&limit=5", true, map[string]string{"limit": "5"}},
+		{"/notes{/id}", "/notes/7", true, map[string]string{"id": "7"}},
+		{"/notes/{x,y}", "/notes/1,2", true, map[string]string{"x": "1", "y": "2"}},
+	}
+
+	for i, td := range dataTable {
+		u, _ := url.Parse(td[1].(string))
+		vars, ok := apib.Match(td[0].(string), u)
+		if ok != td[2].(bool) {
+			t.Errorf("[%v] Match(%q, %q) = %v, want %v", i, td[0], td[1], ok, td[2])
+		}
+
+		if !reflect.DeepEqual(vars, td[3]) {
+			t.Errorf("[%v] vars = %v, want %v", i, vars, td[3])
+		}
+	}
+}
+
+func Test_Router_should_route_by_method_and_template(t *testing.T) {
+	var rt apib.Router
+	rt.Handle("GET", "/notes/{id}", func(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+		apib.Respond(w, http.StatusOK, map[string]string{"id": vars["id"]}, nil)
+	})
+	rt.Handle("DELETE", "/notes/{id}", func(w http.ResponseWriter, r *http.Request, vars map[string]string) {
+		apib.Respond(w, http.StatusNoContent, nil, nil)
+	})
+
+	// method, url, status, body
+	dataTable := [][]interface{}{
+		{"GET", "/notes/1", 200, "{\"id\":\"1\"}\n"},
+		{"DELETE", "/notes/1", 204, ""},
+		{"PUT", "/notes/1", 405, "Method Not Allowed\n"},
+		{"GET", "/tags", 404, "404 page not found\n"},
+	}
+
+	for i, td := range dataTable {
+		w := httptest.NewRecorder()
+		rt.ServeHTTP(w, httptest.NewRequest(td[0].(string), td[1].(string), nil))
+
+		if w.Code != td[2].(int) || w.Body.String() != td[3].(string) {
+			t.Errorf("[%v] %v %v = %v %q, want %v %q", i, td[0], td[1], w.Code, w.Body.String(), td[2], td[3])
+		}
+	}
+
+	w := httptest.NewRecorder()
+	rt.ServeHTTP(w, httptest.NewRequest("PUT", "/notes/1", nil))
+	if allow := w.Header().Get("Allow"); allow != "DELETE, GET" {
+		t.Errorf("Allow = %q, want DELETE, GET", allow)
+	}
+}
+
+func Test_Respond_should_write_status_errors(t *testing.T) {
+	w := httptest.NewRecorder()
+	apib.Respond(w, http.StatusOK, nil, &apib.StatusError{StatusCode: http.StatusConflict, Body: []byte("taken")})
+
+	if w.Code != http.StatusConflict || w.Body.String() != "taken" {
+		t.Errorf("response = %v %q, want 409 taken", w.Code, w.Body.String())
+	}
+}
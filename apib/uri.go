@@ -0,0 +1,213 @@
+package apib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// operator describes the expansion of an RFC 6570 expression type.
+type operator struct {
+	first    string
+	sep      string
+	named    bool
+	reserved bool
+}
+
+var operators = map[byte]operator{
+	'+': {"", ",", false, true},
+	'#': {"#", ",", false, true},
+	'.': {".", ".", false, false},
+	'/': {"/", "/", false, false},
+	';': {";", ";", true, false},
+	'?': {"?", "&", true, false},
+	'&': {"&", "&", true, false},
+}
+
+// expression is a {...} part of a URI template.
+type expression struct {
+	op   operator
+	vars []varSpec
+}
+
+// varSpec is a variable of an expression with its prefix length, 0 when the
+// whole value is used.
+type varSpec struct {
+	name   string
+	prefix int
+}
+
+// parseTemplate splits template into literals and expressions, parts alternate
+// starting with a literal.
+func parseTemplate(template string) (literals []string, exprs []expression) {
+	for {
+		open := strings.IndexByte(template, '{')
+		if open < 0 {
+			break
+		}
+		end := strings.IndexByte(template[open:], '}')
+		if end < 0 {
+			break
+		}
+
+		literals = append(literals, template[:open])
+		exprs = append(exprs, parseExpression(template[open+1:open+end]))
+		template = template[open+end+1:]
+	}
+
+	return append(literals, template), exprs
+}
+
+func parseExpression(s string) expression {
+	var e expression
+	if len(s) > 0 {
+		if op, ok := operators[s[0]]; ok {
+			e.op = op
+			s = s[1:]
+		}
+	}
+	if e.op.sep == "" {
+		e.op.sep = ","
+	}
+
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSuffix(strings.TrimSpace(spec), "*")
+		v := varSpec{name: spec}
+		if i := strings.IndexByte(spec, ':'); i >= 0 {
+			v.name = spec[:i]
+			v.prefix, _ = strconv.Atoi(spec[i+1:])
+		}
+		if v.name != "" {
+			e.vars = append(e.vars, v)
+		}
+	}
+
+	return e
+}
+
+// Variables returns the names of the variables in the URI template in the
+// order of their first use, e.g. id and limit for /notes/{id}{?limit}.
+func Variables(template string) []string {
+	var names []string
+	seen := map[string]bool{}
+
+	_, exprs := parseTemplate(template)
+	for _, e := range exprs {
+		for _, v := range e.vars {
+			if !seen[v.name] {
+				seen[v.name] = true
+				names = append(names, v.name)
+			}
+		}
+	}
+
+	return names
+}
+
+// SplitTemplate returns the path of the URI template with each path
+// expression reduced to its {name} form, the path variables and the query
+// variables. /notes/{id}{?limit} has the path /notes/{id}, the path variable
+// id and the query variable limit.
+func SplitTemplate(template string) (path string, pathVars, queryVars []string) {
+	literals, exprs := parseTemplate(template)
+
+	var buf strings.Builder
+	inQuery := false
+	for i, literal := range literals {
+		if j := strings.IndexByte(literal, '?'); j >= 0 && !inQuery {
+			buf.WriteString(literal[:j])
+			inQuery = true
+		} else if !inQuery {
+			buf.WriteString(literal)
+		}
+
+		if i == len(exprs) {
+			break
+		}
+
+		e := exprs[i]
+		for j, v := range e.vars {
+			switch {
+			case e.op.named && e.op.first != ";" || inQuery:
+				queryVars = append(queryVars, v.name)
+				continue
+			case e.op.first == "#":
+				continue
+			case j == 0:
+				buf.WriteString(e.op.first)
+			default:
+				buf.WriteString(e.op.sep)
+			}
+			buf.WriteString("{" + v.name + "}")
+			pathVars = append(pathVars, v.name)
+		}
+	}
+
+	return buf.String(), pathVars, queryVars
+}
+
+// Expand expands the RFC 6570 URI template with vars. Variables that are
+// missing or empty are undefined and omitted, /notes{?limit} expands to
+// /notes when limit is empty.
+func Expand(template string, vars map[string]string) string {
+	literals, exprs := parseTemplate(template)
+
+	var buf strings.Builder
+	for i, literal := range literals {
+		buf.WriteString(literal)
+		if i < len(exprs) {
+			buf.WriteString(exprs[i].expand(vars))
+		}
+	}
+
+	return buf.String()
+}
+
+func (e expression) expand(vars map[string]string) string {
+	var parts []string
+	for _, v := range e.vars {
+		value := vars[v.name]
+		if value == "" {
+			continue
+		}
+
+		if runes := []rune(value); v.prefix > 0 && v.prefix < len(runes) {
+			value = string(runes[:v.prefix])
+		}
+
+		value = escape(value, e.op.reserved)
+		if e.op.named {
+			value = fmt.Sprintf("%v=%v", v.name, value)
+		}
+		parts = append(parts, value)
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return e.op.first + strings.Join(parts, e.op.sep)
+}
+
+// escape percent-encodes every byte of s that is not unreserved, reserved
+// characters such as / are kept when reserved is true.
+func escape(s string, reserved bool) string {
+	const hex = "0123456789ABCDEF"
+
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', strings.IndexByte("-._~", c) >= 0:
+			buf.WriteByte(c)
+		case reserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0:
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&15])
+		}
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,68 @@
+package apib_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+func Test_Expand_should_expand_uri_templates(t *testing.T) {
+	vars := map[string]string{
+		"id":    "42",
+		"path":  "a/b",
+		"q":     "red apple",
+		"limit": "10",
+		"empty": "",
+	}
+
+	// template, expected
+	dataTable := [][]interface{}{
+		{"/notes", "/notes"},
+		{"/notes/{id}", "/notes/42"},
+		{"/files/{path}", "/files/a%2Fb"},
+		{"/files/{+path}", "/files/a/b"},
+		{"/notes{?q,limit}", "/notes?q=red%20apple&limit=10"},
+		{"/notes{?empty,missing}", "/notes"},
+		{"/notes?sort=id{&limit}", "/notes?sort=id&limit=10"},
+		{"/notes{/id}", "/notes/42"},
+		{"/notes/{id}{.empty}", "/notes/42"},
+		{"/notes{;id}", "/notes;id=42"},
+		{"/notes{#path}", "/notes#a/b"},
+		{"/notes/{q:3}", "/notes/red"},
+		{"/notes/{id*}", "/notes/42"},
+	}
+
+	for i, td := range dataTable {
+		actual := apib.Expand(td[0].(string), vars)
+		if actual != td[1].(string) {
+			t.Errorf("[%v] Expand(%q) = %q, want %q", i, td[0], actual, td[1])
+		}
+	}
+}
+
+func Test_Variables_should_list_names_in_order(t *testing.T) {
+	actual := apib.Variables("/notes/{id}/tags/{tag}{?limit,id}")
+	expected := []string{"id", "tag", "limit"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Variables() = %v, want %v", actual, expected)
+	}
+}
+
+func Test_SplitTemplate_should_separate_path_and_query(t *testing.T) {
+	// template, path, path vars, query vars
+	dataTable := [][]interface{}{
+		{"/notes", "/notes", []string(nil), []string(nil)},
+		{"/notes/{id}{?limit,q}", "/notes/{id}", []string{"id"}, []string{"limit", "q"}},
+		{"/files/{+path}", "/files/{path}", []string{"path"}, []string(nil)},
+		{"/notes{/id}", "/notes/{id}", []string{"id"}, []string(nil)},
+		{"/notes?sort=id{&limit}", "/notes", []string(nil), []string{"limit"}},
+	}
+
+	for i, td := range dataTable {
+		path, pathVars, queryVars := apib.SplitTemplate(td[0].(string))
+		if path != td[1].(string) || !reflect.DeepEqual(pathVars, td[2]) || !reflect.DeepEqual(queryVars, td[3]) {
+			t.Errorf("[%v] SplitTemplate(%q) = %q %v %v, want %q %v %v", i, td[0], path, pathVars, queryVars, td[1], td[2], td[3])
+		}
+	}
+}
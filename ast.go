@@ -0,0 +1,299 @@
+package main
+
+// Property is a single member of a data structure.
+type Property struct {
+	Description string
+	Name        string
+	Type        string
+	IsArray     bool
+	IsEnum      bool
+	Value       string
+	Pos         Position
+
+	// Properties are the nested members of an inline object or enum.
+	Properties []*Property
+
+	// Include names the data structure mixed in by `+ Include Name`, the
+	// other fields are unused when it is set.
+	Include string
+
+	// OneOf marks a `+ One Of` section, each of the Properties is an
+	// alternative. An alternative without a name is a group of properties.
+	OneOf bool
+
+	// MSON type attributes.
+	Required  bool
+	Optional  bool
+	Fixed     bool
+	FixedType bool
+	Nullable  bool
+	Sample    bool
+	Default   bool
+}
+
+// SetAttr sets the type attribute named attr, it returns false when attr is
+// not a known attribute.
+func (p *Property) SetAttr(attr string) bool {
+	switch attr {
+	case "required":
+		p.Required = true
+	case "optional":
+		p.Optional = true
+	case "fixed":
+		p.Fixed = true
+	case "fixed-type":
+		p.FixedType = true
+	case "nullable":
+		p.Nullable = true
+	case "sample":
+		p.Sample = true
+	case "default":
+		p.Default = true
+	default:
+		return false
+	}
+	return true
+}
+
+// IsInline reports whether the property declares its own object members.
+func (p *Property) IsInline() bool {
+	return len(p.Properties) > 0 && !p.IsEnum && !p.OneOf && (p.Type == "" || p.Type == "object")
+}
+
+// IsTypeEnum reports whether the property is an enum of named types, e.g.
+// (enum) with the members (Card) and (Bank), which is a sum type.
+func (p *Property) IsTypeEnum() bool {
+	return p.IsEnum && isTypeEnum(p.Properties)
+}
+
+func isTypeEnum(members []*Property) bool {
+	if len(members) == 0 {
+		return false
+	}
+
+	for _, member := range members {
+		if member.Name != "" || isBaseType(member.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// isBaseType reports whether t is an MSON base type rather than a named type.
+func isBaseType(t string) bool {
+	switch t {
+	case "", "object", "string", "number", "boolean", "array", "enum":
+		return true
+	}
+	return false
+}
+
+// IsInlineEnum reports whether the property declares its own enum members.
+func (p *Property) IsInlineEnum() bool {
+	return len(p.Properties) > 0 && p.IsEnum && !p.IsTypeEnum()
+}
+
+// DataStructure is a named type declared in the Data Structures section.
+// Type is the base type, for enums and arrays it is the type of the members.
+type DataStructure struct {
+	Description string
+	Name        string
+	Type        string
+	IsArray     bool
+	IsEnum      bool
+	Properties  []*Property
+	Pos         Position
+}
+
+// IsTypeEnum reports whether the data structure is an enum of named types.
+func (ds *DataStructure) IsTypeEnum() bool {
+	return ds.IsEnum && isTypeEnum(ds.Properties)
+}
+
+// Base returns the named type the data structure inherits from, or "" when
+// it derives from an MSON base type.
+func (ds *DataStructure) Base() string {
+	if ds.IsEnum || ds.IsArray {
+		return ""
+	}
+
+	if isBaseType(ds.Type) {
+		return ""
+	}
+
+	return ds.Type
+}
+
+// MetaData is a key-value pair from the Meta Section.
+type MetaData struct {
+	Key   string
+	Value string
+	Pos   Position
+}
+
+// Document is the root of a parsed blueprint.
+type Document struct {
+	Title          string
+	Overview       string
+	MetaData       []*MetaData
+	DataStructures []*DataStructure
+	ResourceGroups []*ResourceGroup
+	Pos            Position
+}
+
+// ResourceGroup is a `# Group Name` section. Resources declared outside of a
+// group are collected in a group without a name.
+type ResourceGroup struct {
+	Name        string
+	Description string
+	Resources   []*Resource
+	Pos         Position
+}
+
+// Resource is a `## Name [/uri/{template}]` section.
+type Resource struct {
+	Name        string
+	URITemplate string
+	Description string
+	Actions     []*Action
+	Pos         Position
+
+	// Attributes describe the resource, nil when there is no section.
+	Attributes *DataStructure
+	// Parameters describe the URI template variables, each property is a
+	// variable with its example value.
+	Parameters *DataStructure
+}
+
+// Action is a `### Name [METHOD]` section. URITemplate defaults to the URI
+// template of the enclosing resource.
+type Action struct {
+	Name        string
+	Method      string
+	URITemplate string
+	Description string
+	Requests    []*Request
+	Responses   []*Response
+	Pos         Position
+
+	// Attributes describe the request body shared by the requests.
+	Attributes *DataStructure
+	// Parameters describe the URI template variables of the action.
+	Parameters *DataStructure
+}
+
+// Transaction is a request example and a response documented for it.
+type Transaction struct {
+	Request  *Request
+	Response *Response
+}
+
+// Transactions pairs each request with the responses that follow it up to the
+// next request. Responses before the first request have a nil Request.
+func (a *Action) Transactions() []Transaction {
+	var transactions []Transaction
+	for _, response := range a.Responses {
+		var request *Request
+		for _, r := range a.Requests {
+			if r.Pos.Offset < response.Pos.Offset {
+				request = r
+			}
+		}
+		transactions = append(transactions, Transaction{request, response})
+	}
+	return transactions
+}
+
+// Parameter returns the parameter of the action or its resource called name,
+// or nil.
+func (a *Action) Parameter(resource *Resource, name string) *Property {
+	for _, params := range []*DataStructure{a.Parameters, resource.Parameters} {
+		if params == nil {
+			continue
+		}
+		for _, p := range params.Properties {
+			if p.Name == name {
+				return p
+			}
+		}
+	}
+	return nil
+}
+
+// Payload is the content shared by requests and responses.
+type Payload struct {
+	MediaType string
+	Headers   map[string]string
+	Body      string
+	Schema    string
+	Pos       Position
+
+	// Attributes describe the body, nil when there is no section. Type names
+	// the data structure and Properties holds inline members.
+	Attributes *DataStructure
+}
+
+// Request is a `+ Request name (media/type)` section of an action.
+type Request struct {
+	Name string
+	Payload
+}
+
+// Response is a `+ Response 200 (media/type)` section of an action.
+type Response struct {
+	StatusCode int
+	Payload
+}
+
+// Resources returns the resources of every group in document order.
+func (doc *Document) Resources() []*Resource {
+	var resources []*Resource
+	for _, group := range doc.ResourceGroups {
+		resources = append(resources, group.Resources...)
+	}
+	return resources
+}
+
+// Lookup returns the data structure called name or nil.
+func (doc *Document) Lookup(name string) *DataStructure {
+	for _, ds := range doc.DataStructures {
+		if ds.Name == name {
+			return ds
+		}
+	}
+	return nil
+}
+
+// Expand returns properties with each Include replaced by the members of the
+// included data structure. Includes of unknown types and cycles are dropped,
+// Parse reports both as errors.
+func (doc *Document) Expand(properties []*Property) []*Property {
+	return doc.expand(properties, map[string]bool{})
+}
+
+func (doc *Document) expand(properties []*Property, seen map[string]bool) []*Property {
+	expanded := make([]*Property, 0, len(properties))
+	for _, property := range properties {
+		if property.Include == "" {
+			expanded = append(expanded, property)
+			continue
+		}
+
+		ds := doc.Lookup(property.Include)
+		if ds == nil || seen[ds.Name] {
+			continue
+		}
+
+		seen[ds.Name] = true
+		expanded = append(expanded, doc.expand(ds.Properties, seen)...)
+		delete(seen, ds.Name)
+	}
+	return expanded
+}
+
+func NewDoc() *Document {
+	return &Document{
+		MetaData:       make([]*MetaData, 0, 10),
+		DataStructures: make([]*DataStructure, 0, 10),
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BlueprintWriter writes documents and data structures as API Blueprint in
+// a canonical form.
+type BlueprintWriter struct {
+	io.Writer
+	// SortMetaData writes the metadata ordered by key.
+	SortMetaData bool
+
+	buf bytes.Buffer
+}
+
+func NewBlueprintWriter(w io.Writer) *BlueprintWriter {
+	return &BlueprintWriter{Writer: w}
+}
+
+// WriteDataStructures writes dss as a Data Structures section to include
+// below the API name.
+func (w *BlueprintWriter) WriteDataStructures(dss []*DataStructure) error {
+	w.buf.Reset()
+	w.writeDataStructures("##", dss)
+	_, err := w.Write(w.buf.Bytes())
+	return err
+}
+
+// WriteDoc writes doc with the metadata and API name first, followed by the
+// resource groups and the data structures. Groups, the Data Structures
+// section and the API name are level 1 headers, resources and data
+// structures level 2 and actions level 3. List items use a four space
+// indent and assets are indented eight spaces below their section.
+func (w *BlueprintWriter) WriteDoc(doc *Document) error {
+	w.buf.Reset()
+
+	metadata := append([]*MetaData(nil), doc.MetaData...)
+	if w.SortMetaData {
+		sort.SliceStable(metadata, func(i, j int) bool {
+			return metadata[i].Key < metadata[j].Key
+		})
+	}
+	for _, md := range metadata {
+		fmt.Fprintf(&w.buf, "%v: %v\n", md.Key, md.Value)
+	}
+	if len(metadata) > 0 {
+		fmt.Fprintf(&w.buf, "\n")
+	}
+
+	if doc.Title != "" {
+		fmt.Fprintf(&w.buf, "# %v\n", strings.TrimSpace(doc.Title))
+	}
+	w.writeDescription(doc.Overview)
+
+	for _, group := range doc.ResourceGroups {
+		if group.Name != "" {
+			fmt.Fprintf(&w.buf, "\n# Group %v\n", group.Name)
+			w.writeDescription(group.Description)
+		}
+		for _, resource := range group.Resources {
+			w.writeResource(resource)
+		}
+	}
+
+	if len(doc.DataStructures) > 0 {
+		fmt.Fprintf(&w.buf, "\n")
+		w.writeDataStructures("#", doc.DataStructures)
+	}
+
+	_, err := w.Write(bytes.TrimLeft(w.buf.Bytes(), "\n"))
+	return err
+}
+
+// writeDescription writes the text below a header.
+func (w *BlueprintWriter) writeDescription(text string) {
+	if text != "" {
+		fmt.Fprintf(&w.buf, "%v\n", text)
+	}
+}
+
+// writeResource writes resource and its actions. A resource without a name
+// with a single action for its URI template is written as `## METHOD /uri`.
+func (w *BlueprintWriter) writeResource(resource *Resource) {
+	if len(resource.Actions) == 1 {
+		action := resource.Actions[0]
+		if resource.Name == "" && action.Name == "" && resource.Description == "" &&
+			resource.Parameters == nil && resource.Attributes == nil && action.URITemplate == resource.URITemplate {
+			fmt.Fprintf(&w.buf, "\n## %v %v\n", action.Method, action.URITemplate)
+			w.writeActionBody(action)
+			return
+		}
+	}
+
+	if resource.Name == "" {
+		fmt.Fprintf(&w.buf, "\n## %v\n", resource.URITemplate)
+	} else {
+		fmt.Fprintf(&w.buf, "\n## %v [%v]\n", resource.Name, resource.URITemplate)
+	}
+	w.writeDescription(resource.Description)
+	w.writeSection("", "Parameters", resource.Parameters)
+	w.writeSection("", "Attributes", resource.Attributes)
+
+	for _, action := range resource.Actions {
+		// a method without a name or brackets needs the URI to be an action.
+		uri := ""
+		if action.Name == "" || action.URITemplate != resource.URITemplate {
+			uri = " " + action.URITemplate
+		}
+
+		if action.Name == "" {
+			fmt.Fprintf(&w.buf, "\n### %v%v\n", action.Method, uri)
+		} else {
+			fmt.Fprintf(&w.buf, "\n### %v [%v%v]\n", action.Name, action.Method, uri)
+		}
+		w.writeActionBody(action)
+	}
+}
+
+// writeActionBody writes the description, sections, requests and responses
+// of action, the requests and responses in document order so each response
+// stays with its request.
+func (w *BlueprintWriter) writeActionBody(action *Action) {
+	w.writeDescription(action.Description)
+	w.writeSection("", "Parameters", action.Parameters)
+	w.writeSection("", "Attributes", action.Attributes)
+
+	requests, responses := action.Requests, action.Responses
+	for len(requests) > 0 || len(responses) > 0 {
+		if len(requests) > 0 && (len(responses) == 0 || requests[0].Pos.Offset < responses[0].Pos.Offset) {
+			r := requests[0]
+			requests = requests[1:]
+			w.writePayload(payloadTitle("Request", r.Name, r.MediaType), r.Payload)
+			continue
+		}
+
+		r := responses[0]
+		responses = responses[1:]
+		w.writePayload(payloadTitle("Response", strconv.Itoa(r.StatusCode), r.MediaType), r.Payload)
+	}
+}
+
+// payloadTitle returns the list item text of a request or response.
+func payloadTitle(keyword, id, mediaType string) string {
+	title := keyword
+	if id != "" {
+		title += " " + id
+	}
+	if mediaType != "" {
+		title += " (" + mediaType + ")"
+	}
+	return title
+}
+
+// writePayload writes a request or response with its Headers, Attributes,
+// Body and Schema sections.
+func (w *BlueprintWriter) writePayload(title string, payload Payload) {
+	fmt.Fprintf(&w.buf, "\n+ %v\n", title)
+
+	if len(payload.Headers) > 0 {
+		names := make([]string, 0, len(payload.Headers))
+		for name := range payload.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var headers []string
+		for _, name := range names {
+			headers = append(headers, name+": "+payload.Headers[name])
+		}
+		w.writeAsset("Headers", strings.Join(headers, "\n"))
+	}
+	w.writeSection("    ", "Attributes", payload.Attributes)
+	w.writeAsset("Body", payload.Body)
+	w.writeAsset("Schema", payload.Schema)
+}
+
+// writeAsset writes a payload section with the asset text indented below
+// it.
+func (w *BlueprintWriter) writeAsset(keyword, asset string) {
+	if asset == "" {
+		return
+	}
+
+	fmt.Fprintf(&w.buf, "\n    + %v\n\n", keyword)
+	for _, line := range strings.Split(asset, "\n") {
+		if strings.TrimSpace(line) == "" {
+			fmt.Fprintf(&w.buf, "\n")
+			continue
+		}
+		fmt.Fprintf(&w.buf, "            %v\n", line)
+	}
+}
+
+// writeSection writes a Parameters or Attributes section at indent with the
+// members of ds, nothing when ds is nil.
+func (w *BlueprintWriter) writeSection(indent, keyword string, ds *DataStructure) {
+	if ds == nil {
+		return
+	}
+
+	fmt.Fprintf(&w.buf, "\n%v+ %v", indent, keyword)
+	if spec := typeSpec(ds.Type, ds.IsArray, ds.IsEnum); spec != "" {
+		fmt.Fprintf(&w.buf, " (%v)", spec)
+	}
+	fmt.Fprintf(&w.buf, "\n")
+	w.writeProperties(indent+"    ", ds.Properties)
+}
+
+// writeDataStructures writes a Data Structures section with header level
+// followed by a header one level lower for each of dss.
+func (w *BlueprintWriter) writeDataStructures(level string, dss []*DataStructure) {
+	fmt.Fprintf(&w.buf, "%v Data Structures\n", level)
+	for _, ds := range dss {
+		fmt.Fprintf(&w.buf, "\n%v# %v", level, ds.Name)
+		if spec := typeSpec(ds.Type, ds.IsArray, ds.IsEnum); spec != "" {
+			fmt.Fprintf(&w.buf, " (%v)", spec)
+		}
+		fmt.Fprintf(&w.buf, "\n")
+
+		if ds.Description != "" {
+			fmt.Fprintf(&w.buf, "%v\n", ds.Description)
+		}
+		if len(ds.Properties) > 0 {
+			if ds.Description != "" {
+				fmt.Fprintf(&w.buf, "\n")
+			}
+			w.writeProperties("", ds.Properties)
+		}
+	}
+}
+
+// writeProperties writes a list item for each of properties at indent.
+func (w *BlueprintWriter) writeProperties(indent string, properties []*Property) {
+	for _, p := range properties {
+		switch {
+		case p.Include != "":
+			fmt.Fprintf(&w.buf, "%v+ Include %v\n", indent, p.Include)
+			continue
+		case p.OneOf:
+			fmt.Fprintf(&w.buf, "%v+ One Of\n", indent)
+			w.writeProperties(indent+"    ", p.Properties)
+			continue
+		case p.Name == "" && p.Type == "":
+			fmt.Fprintf(&w.buf, "%v+ Properties\n", indent)
+			w.writeProperties(indent+"    ", p.Properties)
+			continue
+		}
+
+		line := escapeName(p.Name)
+		if p.Value != "" {
+			if line != "" {
+				line += ": "
+			}
+			line += p.Value
+		}
+
+		var attrs []string
+		if spec := typeSpec(p.Type, p.IsArray, p.IsEnum); spec != "" {
+			attrs = append(attrs, spec)
+		}
+		attrs = append(attrs, typeAttributes(p)...)
+		if len(attrs) > 0 {
+			if line != "" {
+				line += " "
+			}
+			line += "(" + strings.Join(attrs, ", ") + ")"
+		}
+
+		if p.Description != "" {
+			line += " - " + strings.Join(strings.Fields(p.Description), " ")
+		}
+
+		fmt.Fprintf(&w.buf, "%v+ %v\n", indent, line)
+		w.writeProperties(indent+"    ", p.Properties)
+	}
+}
+
+// escapeName returns the property name escaped with backticks when the lexer
+// would not read it back as a name, e.g. `first name` or a keyword.
+func escapeName(name string) string {
+	if name == "" {
+		return name
+	}
+
+	plain := !typeSections[name] && name != "Include"
+	for _, r := range name {
+		if !Letter(r) && !Number(r) && !strings.ContainsRune("_-.", r) {
+			plain = false
+		}
+	}
+	if plain {
+		return name
+	}
+	return "`" + name + "`"
+}
+
+// typeSpec returns the MSON type definition of a base or named type t, e.g.
+// array[Tag] or enum[string].
+func typeSpec(t string, isArray, isEnum bool) string {
+	switch {
+	case isArray && (t == "" || t == "array"):
+		return "array"
+	case isArray:
+		return "array[" + t + "]"
+	case isEnum && (t == "" || t == "enum"):
+		return "enum"
+	case isEnum:
+		return "enum[" + t + "]"
+	}
+	return t
+}
+
+// typeAttributes returns the MSON type attributes set on p.
+func typeAttributes(p *Property) []string {
+	var attrs []string
+	for _, attr := range []struct {
+		set  bool
+		name string
+	}{
+		{p.Required, "required"},
+		{p.Optional, "optional"},
+		{p.Fixed, "fixed"},
+		{p.FixedType, "fixed-type"},
+		{p.Nullable, "nullable"},
+		{p.Sample, "sample"},
+		{p.Default, "default"},
+	} {
+		if attr.set {
+			attrs = append(attrs, attr.name)
+		}
+	}
+	return attrs
+}
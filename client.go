@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// writeClient writes a Client with a method for each action of doc, the
+// methods expand the URI template, send the request attributes as JSON and
+// decode the attributes of the 2xx response.
+func (w *GoWriter) writeClient(doc *Document, endpoints []*endpoint) {
+	w.use("context", "")
+	w.use("net/http", "")
+	w.use("strings", "")
+
+	api := "the API"
+	if doc.Title != "" {
+		api = "the " + doc.Title
+	}
+
+	w.Write(bs("// Client sends requests to %v.\n", api))
+	w.Write(bs("type Client struct {\n"))
+	w.Write(bs("\t// BaseURL is prepended to the path of each request, e.g. https://api.example.com.\n"))
+	w.Write(bs("\tBaseURL string\n"))
+	w.Write(bs("\t// HTTPClient sends the requests, http.DefaultClient is used when nil.\n"))
+	w.Write(bs("\tHTTPClient *http.Client\n"))
+	w.Write(bs("}\n\n"))
+
+	w.Write(bs("// NewClient returns a Client for the API at baseURL.\n"))
+	w.Write(bs("func NewClient(baseURL string) *Client {\n"))
+	w.Write(bs("\treturn &Client{BaseURL: strings.TrimRight(baseURL, \"/\")}\n"))
+	w.Write(bs("}\n\n"))
+
+	for _, e := range endpoints {
+		w.writeClientMethod(e)
+	}
+}
+
+// writeClientMethod writes the Client method that calls e.
+func (w *GoWriter) writeClientMethod(e *endpoint) {
+	w.use(apibImport, "")
+
+	params := []string{"ctx context.Context"}
+	for _, p := range e.Params {
+		params = append(params, p+" string")
+	}
+	body := "nil"
+	if e.Request != "" {
+		params = append(params, "body "+e.Request)
+		body = "body"
+	}
+
+	results := "error"
+	if e.Response != "" {
+		results = fmt.Sprintf("(%v, error)", e.Response)
+	}
+
+	w.Write(bs("// %v sends %v %v.\n", e.Name, e.Method, e.URITemplate))
+	if e.Description != "" {
+		w.Write(bs("//\n"))
+		w.writeComment("", e.Description)
+	}
+	w.Write(bs("func (c *Client) %v(%v) %v {\n", e.Name, strings.Join(params, ", "), results))
+
+	uri := fmt.Sprintf("%q", e.URITemplate)
+	if len(e.Vars) > 0 {
+		vars := make([]string, 0, len(e.Vars))
+		for i, v := range e.Vars {
+			vars = append(vars, fmt.Sprintf("%q: %v", v, e.Params[i]))
+		}
+		w.Write(bs("\turi := apib.Expand(%q, map[string]string{%v})\n", e.URITemplate, strings.Join(vars, ", ")))
+		uri = "uri"
+	}
+
+	if e.Response == "" {
+		w.Write(bs("\treturn apib.Do(ctx, c.HTTPClient, %q, c.BaseURL+%v, %v, nil)\n", e.Method, uri, body))
+		w.Write(bs("}\n\n"))
+		return
+	}
+
+	out := "&out"
+	if strings.HasPrefix(e.Response, "*") {
+		w.Write(bs("\tout := new(%v)\n", e.Response[1:]))
+		out = "out"
+	} else {
+		w.Write(bs("\tvar out %v\n", e.Response))
+	}
+	w.Write(bs("\tif err := apib.Do(ctx, c.HTTPClient, %q, c.BaseURL+%v, %v, %v); err != nil {\n", e.Method, uri, body, out))
+	w.Write(bs("\t\treturn nil, err\n"))
+	w.Write(bs("\t}\n"))
+	w.Write(bs("\treturn out, nil\n"))
+	w.Write(bs("}\n\n"))
+}
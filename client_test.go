@@ -0,0 +1,92 @@
+package main_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+var clientDoc = `# Notes API
+
+## GET /health
+
+## Notes [/notes{?limit}]
+
+### List Notes [GET]
+
++ Response 200 (application/json)
+    + Attributes (array[Note])
+
+### Create a Note [POST]
+Adds a note.
+
++ Request (application/json)
+    + Attributes
+        + title (string, required)
+
++ Response 201 (application/json)
+    + Attributes (Note)
+
+## Note [/notes/{id}]
+
+### [DELETE]
+
++ Response 204
+
+## Data Structures
+
+### Note
++ id: 1 (number, required)`
+
+func Test_GoWriter_WriteDoc_should_write_client(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("notes.apib", clientDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	w := NewGoWriter(&buf, "notes")
+	w.Client = true
+	if err := w.WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	src := buf.String()
+
+	expected := []string{
+		"type CreateANoteRequest struct { Title string `json:\"title\"` }",
+		"// Client sends requests to the Notes API.",
+		"func NewClient(baseURL string) *Client {",
+		`func (c *Client) GetHealth(ctx context.Context) error {
+	return apib.Do(ctx, c.HTTPClient, "GET", c.BaseURL+"/health", nil, nil)
+}`,
+		`func (c *Client) ListNotes(ctx context.Context, limit string) ([]*Note, error) {
+	uri := apib.Expand("/notes{?limit}", map[string]string{"limit": limit})
+	var out []*Note
+	if err := apib.Do(ctx, c.HTTPClient, "GET", c.BaseURL+uri, nil, &out); err != nil {`,
+		`// CreateANote sends POST /notes{?limit}.
+//
+// Adds a note.
+func (c *Client) CreateANote(ctx context.Context, limit string, body *CreateANoteRequest) (*Note, error) {`,
+		`out := new(Note)
+	if err := apib.Do(ctx, c.HTTPClient, "POST", c.BaseURL+uri, body, out); err != nil {`,
+		"func (c *Client) DeleteNote(ctx context.Context, id string) error {",
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_omit_client_by_default(t *testing.T) {
+	t.Parallel()
+
+	src := writeGo(t, clientDoc)
+	if containsGo(src, "type Client struct") {
+		t.Errorf("unexpected Client in:\n%v", src)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+// WriteContract writes VerifyContract, which sends the request examples of
+// doc to an http.Handler and reports responses that differ from the
+// blueprint. The source belongs in a _test.go file next to the models
+// written with the same Client and Server settings, without either the
+// types of the action payloads are written here as WriteDoc omits them.
+func (w *GoWriter) WriteContract(doc *Document) error {
+	return w.writeFile(doc, false, func() {
+		if !w.Client && !w.Server {
+			w.writeEndpointTypes(doc)
+		}
+		w.writeContract(doc)
+	})
+}
+
+func (w *GoWriter) writeContract(doc *Document) {
+	w.use("net/http", "")
+	w.use(apibImport, "")
+
+	// the names match the models written for doc.
+	endpoints, _ := w.endpoints(doc)
+
+	api := "the API"
+	if doc.Title != "" {
+		api = "the " + doc.Title
+	}
+
+	w.Write(bs("// VerifyContract sends the request examples of %v to h and reports\n", api))
+	w.Write(bs("// responses that differ from the blueprint on t.\n"))
+	w.Write(bs("func VerifyContract(t apib.Reporter, h http.Handler) {\n"))
+	w.Write(bs("\tapib.Verify(t, h, []apib.Exchange{\n"))
+	for _, e := range endpoints {
+		for _, tx := range e.Transactions() {
+			w.writeExchange(e, tx)
+		}
+	}
+	w.Write(bs("\t})\n"))
+	w.Write(bs("}\n"))
+}
+
+// writeExchange writes the apib.Exchange literal for a transaction of e.
+func (w *GoWriter) writeExchange(e *endpoint, tx Transaction) {
+	name := e.Name
+	request := &Request{}
+	if tx.Request != nil {
+		request = tx.Request
+		if request.Name != "" {
+			name += " " + request.Name
+		}
+	}
+	name += " " + strconv.Itoa(tx.Response.StatusCode)
+
+	vars := map[string]string{}
+	for _, v := range apib.Variables(e.URITemplate) {
+		if p := e.Parameter(e.Resource, v); p != nil {
+			vars[v] = strings.Trim(p.Value, "`")
+		}
+	}
+
+	w.Write(bs("\t\t{\n"))
+	w.Write(bs("\t\t\tName: %q,\n", name))
+	w.Write(bs("\t\t\tMethod: %q,\n", e.Method))
+	w.Write(bs("\t\t\tURL: %q,\n", apib.Expand(e.URITemplate, vars)))
+	if headers := payloadHeaders(request.Payload); len(headers) > 0 {
+		w.Write(bs("\t\t\tHeader: %v,\n", headers))
+	}
+	if request.Body != "" {
+		w.Write(bs("\t\t\tBody: %v,\n", quote(request.Body)))
+	}
+	w.Write(bs("\t\t\tStatus: %v,\n", tx.Response.StatusCode))
+	if headers := payloadHeaders(tx.Response.Payload); len(headers) > 0 {
+		w.Write(bs("\t\t\tResponseHeader: %v,\n", headers))
+	}
+	if tx.Response.StatusCode == e.Status && e.Response != "" {
+		w.Write(bs("\t\t\tModel: func() interface{} { return new(%v) },\n", strings.TrimPrefix(e.Response, "*")))
+	}
+	w.Write(bs("\t\t},\n"))
+}
+
+// payloadHeaders returns a map literal of the headers of payload including
+// the Content-Type of its media type, or "" when there are none.
+func payloadHeaders(payload Payload) string {
+	headers := map[string]string{}
+	for name, value := range payload.Headers {
+		headers[name] = value
+	}
+
+	hasContentType := false
+	for name := range headers {
+		hasContentType = hasContentType || strings.EqualFold(name, "Content-Type")
+	}
+	if payload.MediaType != "" && !hasContentType {
+		headers["Content-Type"] = payload.MediaType
+	}
+
+	if len(headers) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%q: %q", name, headers[name]))
+	}
+	return fmt.Sprintf("map[string]string{%v}", strings.Join(pairs, ", "))
+}
+
+// quote returns s as a raw string literal when possible.
+func quote(s string) string {
+	if strings.Contains(s, "`") || strings.Contains(s, "\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
@@ -0,0 +1,98 @@
+package main_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+func Test_GoWriter_WriteContract_should_write_exchanges(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("notes.apib", payloadDoc+`
+
+## Notes [/notes]
+
+### Create a Note [POST]
++ Request invalid (application/json)
+
+        {"id": "x"}
+
++ Response 400`)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewGoWriter(&buf, "notes").WriteContract(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	src := buf.String()
+
+	expected := []string{
+		"func VerifyContract(t apib.Reporter, h http.Handler) {",
+		`{
+			Name: "UpdateANote 200",
+			Method: "PUT",
+			URL: "/notes/1",
+			Header: map[string]string{"Authorization": "Bearer abc", "Content-Type": "application/json", "X-Request-ID": "42"},
+			Body: ` + "`" + `{
+    "id": 1
+}` + "`" + `,
+			Status: 200,
+			ResponseHeader: map[string]string{"Content-Type": "application/json"},
+			Model: func() interface{} { return new(UpdateANoteResponse) },
+		},`,
+		`{
+			Name: "UpdateANote 404",
+			Method: "PUT",
+			URL: "/notes/1",`,
+		`{
+			Name: "CreateANote invalid 400",
+			Method: "POST",
+			URL: "/notes",
+			Header: map[string]string{"Content-Type": "application/json"},
+			Body: ` + "`" + `{"id": "x"}` + "`" + `,
+			Status: 400,
+		},`,
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+
+	if containsGo(src, "Package notes") {
+		t.Errorf("unexpected package documentation in:\n%v", src)
+	}
+}
+
+func Test_GoWriter_WriteContract_should_compile_with_the_models(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("notes.apib", payloadDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	for _, server := range []bool{false, true} {
+		var models, contract bytes.Buffer
+		w := NewGoWriter(&models, "main")
+		w.Server = server
+		if err := w.WriteDoc(doc); err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+		w.Writer = &contract
+		if err := w.WriteContract(doc); err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+
+		goRun(t, map[string]string{
+			"models.go":   models.String(),
+			"contract.go": contract.String(),
+			"main.go":     "package main\n\nfunc main() {}\n",
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+// endpoint is an action with the Go names used to generate a client or server.
+type endpoint struct {
+	*Action
+	Resource *Resource
+	// Name is the Go method name, e.g. GetNote.
+	Name string
+	// Params are the Go parameter names of the URI template variables.
+	Params []string
+	// Vars are the URI template variables in the order of Params.
+	Vars []string
+	// Request and Response are the Go types of the bodies, "" when the
+	// action has no attributes for them.
+	Request  string
+	Response string
+	// Status is the status code of the first 2xx response.
+	Status int
+}
+
+// inlineAttributes is a struct synthesised for attributes with members.
+type inlineAttributes struct {
+	name  string
+	attrs *DataStructure
+}
+
+// endpoints returns an endpoint for every action of doc in document order and
+// the structs to write for attributes declared inline.
+func (w *GoWriter) endpoints(doc *Document) ([]*endpoint, []inlineAttributes) {
+	var endpoints []*endpoint
+	var inlines []inlineAttributes
+
+	// method names share a scope with the fields of the client.
+	methods := goNames{"BaseURL": true, "HTTPClient": true}
+	types := goNames{}
+	for _, ds := range doc.DataStructures {
+		types[GoName(ds.Name)] = true
+	}
+
+	attrsType := func(attrs *DataStructure, name string) string {
+		switch {
+		case attrs == nil:
+			return ""
+		case len(attrs.Properties) > 0 && !attrs.IsArray && !attrs.IsEnum:
+			name = types.unique(name)
+			inlines = append(inlines, inlineAttributes{name, attrs})
+			return "*" + name
+		case attrs.Type == "" || attrs.Type == "object":
+			return "map[string]interface{}"
+		}
+		return w.goType(name, &Property{Type: attrs.Type, IsArray: attrs.IsArray})
+	}
+
+	for _, resource := range doc.Resources() {
+		for _, action := range resource.Actions {
+			e := &endpoint{Action: action, Resource: resource, Name: methods.unique(actionName(resource, action)), Status: 200}
+
+			params := goNames{"c": true, "ctx": true, "body": true, "out": true, "err": true, "uri": true}
+			for _, v := range apib.Variables(action.URITemplate) {
+				e.Vars = append(e.Vars, v)
+				e.Params = append(e.Params, params.unique(GoParam(v)))
+			}
+
+			request := action.Attributes
+			for _, r := range action.Requests {
+				if request == nil {
+					request = r.Attributes
+				}
+			}
+			e.Request = attrsType(request, e.Name+"Request")
+
+			for _, r := range action.Responses {
+				if r.StatusCode >= 200 && r.StatusCode <= 299 {
+					e.Status = r.StatusCode
+					e.Response = attrsType(r.Attributes, e.Name+"Response")
+					break
+				}
+			}
+
+			endpoints = append(endpoints, e)
+		}
+	}
+
+	return endpoints, inlines
+}
+
+// writeEndpointTypes writes the structs of attributes declared inline in the
+// actions of doc and returns the endpoints.
+func (w *GoWriter) writeEndpointTypes(doc *Document) []*endpoint {
+	endpoints, inlines := w.endpoints(doc)
+	for _, inline := range inlines {
+		w.writeDocComment("", inline.name, inline.attrs.Description)
+		w.writeStruct(inline.name, inline.attrs.Base(), inline.attrs.Properties)
+	}
+	return endpoints
+}
+
+// actionName is the Go name of an action, e.g. Get a Note becomes GetANote.
+// Unnamed actions are named after their method and resource, or the literal
+// parts of the URI template when the resource is unnamed.
+func actionName(resource *Resource, action *Action) string {
+	switch {
+	case action.Name != "":
+		return GoName(action.Name)
+	case resource.Name != "":
+		return GoName(strings.ToLower(action.Method) + " " + resource.Name)
+	}
+
+	literals := action.URITemplate
+	for {
+		open := strings.IndexByte(literals, '{')
+		end := strings.IndexByte(literals, '}')
+		if open < 0 || end < open {
+			break
+		}
+		literals = literals[:open] + " " + literals[end+1:]
+	}
+
+	return GoName(strings.ToLower(action.Method) + " " + literals)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Format parses the blueprint src and returns it in the canonical form of
+// BlueprintWriter. Content the parser does not keep, such as + Model
+// sections, is reported as an error instead of being dropped.
+func Format(filename, src string, sortMetaData bool) ([]byte, error) {
+	doc, err := Parse(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := NewBlueprintWriter(&buf)
+	w.SortMetaData = sortMetaData
+	if err := w.WriteDoc(doc); err != nil {
+		return nil, err
+	}
+
+	if dropped := droppedWords(src, buf.String()); len(dropped) > 0 {
+		return nil, fmt.Errorf("%v: formatting would drop %q", filename, dropped)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// sectionKeywords are MSON type sections the parser folds into the member
+// list, they are not written back.
+var sectionKeywords = map[string]bool{
+	"Members":    true,
+	"Items":      true,
+	"Properties": true,
+}
+
+// droppedWords returns the words of src that occur fewer times in out, in the
+// order they first appear in src.
+func droppedWords(src, out string) []string {
+	isSep := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+
+	counts := map[string]int{}
+	for _, word := range strings.FieldsFunc(out, isSep) {
+		counts[word]++
+	}
+
+	var dropped []string
+	seen := map[string]bool{}
+	for _, word := range strings.FieldsFunc(src, isSep) {
+		counts[word]--
+		if counts[word] < 0 && !sectionKeywords[word] && !seen[word] {
+			seen[word] = true
+			dropped = append(dropped, word)
+		}
+	}
+	return dropped
+}
+
+// fmtMain formats the blueprints named by the arguments, or stdin when there
+// are none, and writes them to stdout or back to the files with -w.
+func fmtMain(args []string) {
+	fs := flag.NewFlagSet("fmt", flag.ExitOnError)
+	var write bool
+	var sortMetaData bool
+	fs.BoolVar(&write, "w", false, "Write the result to the source file instead of stdout.")
+	fs.BoolVar(&sortMetaData, "sort-meta", false, "Sort the metadata by key.")
+	fs.Parse(args)
+
+	filenames := fs.Args()
+	if len(filenames) == 0 {
+		filenames = []string{""}
+	}
+
+	failed := false
+	for _, filename := range filenames {
+		if err := formatFile(filename, write, sortMetaData); err != nil {
+			printFormatError(err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// formatFile formats the blueprint in filename, or stdin when it is empty.
+// The file is only rewritten when write is set and the content changes.
+func formatFile(filename string, write, sortMetaData bool) error {
+	var src []byte
+	var err error
+	if filename == "" {
+		src, err = ioutil.ReadAll(os.Stdin)
+		filename, write = "<stdin>", false
+	} else {
+		src, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return err
+	}
+
+	out, err := Format(filename, string(src), sortMetaData)
+	if err != nil {
+		return err
+	}
+
+	if !write {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if bytes.Equal(src, out) {
+		return nil
+	}
+	return ioutil.WriteFile(filename, out, 0644)
+}
+
+// printFormatError prints each parse error of err on its own line.
+func printFormatError(err error) {
+	if errs, ok := err.(ErrorList); ok {
+		for _, e := range errs {
+			fmt.Fprintln(os.Stderr, e)
+		}
+		return
+	}
+	fmt.Fprintln(os.Stderr, err)
+}
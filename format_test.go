@@ -0,0 +1,155 @@
+package main_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+func Test_Format_should_write_canonical_form(t *testing.T) {
+	t.Parallel()
+
+	src := `HOST: https://api.example.com
+FORMAT: 1A
+
+# Fruit API   
+Fruit things.
+
+
+
+## Group Fruit
+
+### Produce [/produce/{id}]
++ Parameters
+  + id (number,required) -   The id.
+
+#### Get [GET]
++ Response 200 (application/json)
+
+        {"id": 1}
+
+## Data Structures
+### Dimension
++ radius   (number)
++ kind (enum)
+  + round
+  + long
+`
+
+	expected := `FORMAT: 1A
+HOST: https://api.example.com
+
+# Fruit API
+Fruit things.
+
+# Group Fruit
+
+## Produce [/produce/{id}]
+
++ Parameters
+    + id (number, required) - The id.
+
+### Get [GET]
+
++ Response 200 (application/json)
+
+    + Body
+
+            {"id": 1}
+
+# Data Structures
+
+## Dimension
++ radius (number)
++ kind (enum)
+    + round
+    + long
+`
+
+	actual, err := Format("fruit.apib", src, true)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	if string(actual) != expected {
+		t.Errorf("Format() =\n%v\nwant:\n%v", string(actual), expected)
+	}
+}
+
+// escapedDoc has property names that must stay escaped with backticks.
+var escapedDoc = `# API
+## Data Structures
+### Person
++ ` + "`first name`" + ` (string, required) - Given name.
++ ` + "`e-mail/work`" + `: a@b.c (string)
++ ` + "`Properties`" + `
++ address (object)
+    + ` + "`post code`" + ` (string)
+`
+
+func Test_Format_should_be_idempotent(t *testing.T) {
+	t.Parallel()
+
+	dataTable := []string{notesDoc, payloadDoc, clientDoc, schemaDoc, protoDoc, escapedDoc}
+
+	for i, src := range dataTable {
+		once, err := Format("notes.apib", src, false)
+		if err != nil {
+			t.Errorf("[%v] err = %v, want nil", i, err)
+			continue
+		}
+
+		twice, err := Format("notes.apib", string(once), false)
+		if err != nil {
+			t.Errorf("[%v] err = %v, want nil for:\n%s", i, err, once)
+			continue
+		}
+
+		if string(once) != string(twice) {
+			t.Errorf("[%v] Format(Format()) =\n%s\nwant:\n%s", i, twice, once)
+		}
+	}
+}
+
+func Test_Format_should_escape_property_names(t *testing.T) {
+	t.Parallel()
+
+	actual, err := Format("person.apib", escapedDoc, false)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	expected := []string{
+		"+ `first name` (string, required) - Given name.\n",
+		"+ `e-mail/work`: a@b.c (string)\n",
+		"+ `Properties`\n",
+		"+ address (object)\n",
+		"    + `post code` (string)\n",
+	}
+
+	for i, e := range expected {
+		if !strings.Contains(string(actual), e) {
+			t.Errorf("[%v] missing %q in:\n%s", i, e, actual)
+		}
+	}
+}
+
+func Test_Format_should_refuse_to_drop_content(t *testing.T) {
+	t.Parallel()
+
+	_, err := Format("notes.apib", `# Notes API
+## Note [/notes/{id}]
+### Get [GET]
++ Model (text/plain)
+
+        hello
+
++ Response 200
+`, false)
+
+	expected := `notes.apib: formatting would drop ["Model" "text" "plain" "hello"]`
+	if err == nil || err.Error() != expected {
+		t.Errorf("err = %v, want %v", err, expected)
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	goparser "go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// goNumbers are the Go types written as MSON numbers.
+var goNumbers = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true, "Number": true,
+}
+
+// ReadGoPackage returns the exported types of the Go package in dir as data
+// structures, in source order. Structs are objects named by their json tags,
+// a string type with constants is an enum, slices are arrays and doc
+// comments are descriptions. Pointers, primitives types and omitempty fields
+// are optional, other fields are required and pointers that are not
+// omitempty are also nullable.
+func ReadGoPackage(dir string) ([]*DataStructure, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		f, err := goparser.ParseFile(fset, filepath.Join(dir, name), nil, goparser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	return readGoFiles(files), nil
+}
+
+// readGoFiles returns the exported types declared in files.
+func readGoFiles(files []*ast.File) []*DataStructure {
+	consts := map[string][]*Property{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.CONST {
+				readConsts(gd, consts)
+			}
+		}
+	}
+
+	var dss []*DataStructure
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if !ts.Name.IsExported() || ts.TypeParams != nil {
+					continue
+				}
+
+				doc := ts.Doc
+				if doc == nil && len(gd.Specs) == 1 {
+					doc = gd.Doc
+				}
+
+				ds := readType(ts.Name.Name, ts.Type, consts[ts.Name.Name])
+				if ds == nil {
+					continue
+				}
+				ds.Description = docText(ts.Name.Name, strings.TrimSpace(doc.Text()))
+				dss = append(dss, ds)
+			}
+		}
+	}
+
+	return dss
+}
+
+// readConsts adds the string constants of a named type in gd to consts as
+// the members of its enum.
+func readConsts(gd *ast.GenDecl, consts map[string][]*Property) {
+	for _, spec := range gd.Specs {
+		vs := spec.(*ast.ValueSpec)
+		ident, ok := vs.Type.(*ast.Ident)
+		if !ok || len(vs.Names) != len(vs.Values) {
+			continue
+		}
+
+		for i, value := range vs.Values {
+			lit, ok := value.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				continue
+			}
+
+			s, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				continue
+			}
+			desc := docText(vs.Names[i].Name, strings.TrimSpace(vs.Doc.Text()+vs.Comment.Text()))
+			member := &Property{Name: s, Description: desc}
+			consts[ident.Name] = append(consts[ident.Name], member)
+		}
+	}
+}
+
+// docText returns the doc comment text of the identifier name as a
+// description, without the leading "name is" or "name:" of godoc style
+// comments, e.g. Produce is a fruit becomes A fruit.
+func docText(name, text string) string {
+	if rest := strings.TrimPrefix(text, name+": "); rest != text {
+		return rest
+	}
+
+	rest := strings.TrimPrefix(text, name+" is ")
+	if rest == text || rest == "" {
+		return text
+	}
+
+	r := []rune(rest)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// readType returns the data structure declared by the type name, or nil
+// when it has no MSON equivalent such as an interface or func.
+func readType(name string, expr ast.Expr, members []*Property) *DataStructure {
+	ds := &DataStructure{Name: name}
+
+	switch t := expr.(type) {
+	case *ast.StructType:
+		ds.Type, ds.Properties = readStruct(t)
+
+	case *ast.ArrayType:
+		ds.IsArray = true
+		ds.Type = elemType(t.Elt)
+
+	case *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return nil
+
+	default:
+		p := readField("", expr, "")
+		ds.Type = p.Type
+		if len(members) > 0 && ds.Type == "string" {
+			ds.IsEnum = true
+			ds.Properties = members
+		}
+	}
+
+	return ds
+}
+
+// readStruct returns the properties of a struct and the first embedded type
+// without a json name, which is its base type. Later embedded types are
+// included.
+func readStruct(st *ast.StructType) (base string, properties []*Property) {
+	for _, field := range st.Fields.List {
+		tag := ""
+		if field.Tag != nil {
+			if s, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(s).Get("json")
+			}
+		}
+		if tag == "-" {
+			continue
+		}
+		jsonName := strings.Split(tag, ",")[0]
+
+		names := field.Names
+		if len(names) == 0 {
+			embedded := typeName(field.Type)
+			if jsonName == "" && embedded == "object" {
+				// the fields of a type of another package are unknown.
+				continue
+			}
+			if jsonName == "" {
+				if base == "" && len(properties) == 0 {
+					base = embedded
+				} else {
+					properties = append(properties, &Property{Include: embedded})
+				}
+				continue
+			}
+			names = []*ast.Ident{embeddedName(field.Type)}
+		}
+
+		for _, ident := range names {
+			if !ident.IsExported() {
+				continue
+			}
+
+			name := jsonName
+			if name == "" {
+				name = ident.Name
+			}
+
+			p := readField(name, field.Type, tag)
+			p.Description = docText(ident.Name, strings.Join(strings.Fields(field.Doc.Text()+field.Comment.Text()), " "))
+			properties = append(properties, p)
+		}
+	}
+
+	return base, properties
+}
+
+// readField returns the property name of the Go type expr with the json tag.
+func readField(name string, expr ast.Expr, tag string) *Property {
+	p := &Property{Name: name}
+	omitempty := strings.Contains(tag, ",omitempty")
+
+	pointer := false
+	if star, ok := expr.(*ast.StarExpr); ok {
+		pointer = true
+		expr = star.X
+	}
+
+	switch t := expr.(type) {
+	case *ast.ArrayType:
+		p.IsArray = true
+		p.Type = elemType(t.Elt)
+		if isByteSlice(t) {
+			p.IsArray = false
+			p.Type = "string"
+		}
+
+	case *ast.StructType:
+		p.Type = "object"
+		_, p.Properties = readStruct(t)
+
+	case *ast.MapType, *ast.InterfaceType:
+		p.Type = "object"
+		pointer = true
+
+	default:
+		n := typeName(expr)
+		switch {
+		case n == "String" || n == "Boolean" || n == "Number":
+			// the optional primitives are pointers.
+			pointer = true
+			p.Type = strings.ToLower(n)
+		default:
+			p.Type = goBaseType(n)
+		}
+	}
+
+	switch {
+	case pointer && !omitempty:
+		p.Nullable = true
+	case !pointer && !omitempty:
+		p.Required = true
+	}
+
+	return p
+}
+
+// elemType returns the MSON type of the members of a slice.
+func elemType(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	switch expr.(type) {
+	case *ast.MapType, *ast.InterfaceType, *ast.StructType:
+		return "object"
+	}
+	return goBaseType(typeName(expr))
+}
+
+// goBaseType returns the MSON type of the Go type name.
+func goBaseType(name string) string {
+	switch {
+	case name == "string" || name == "String":
+		return "string"
+	case name == "bool" || name == "Boolean":
+		return "boolean"
+	case goNumbers[name]:
+		return "number"
+	case name == "any":
+		return "object"
+	}
+	return name
+}
+
+// typeName returns the name of a named type. A type of another package is
+// an object as its declaration is not read, except for the standard library
+// types with an MSON equivalent.
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			switch pkg.Name + "." + t.Sel.Name {
+			case "time.Time":
+				return "string"
+			case "json.Number":
+				return "number"
+			case "json.RawMessage":
+				return "any"
+			}
+		}
+		return "object"
+	case *ast.StarExpr:
+		return typeName(t.X)
+	}
+	return ""
+}
+
+// embeddedName returns the field name of the embedded type expr.
+func embeddedName(expr ast.Expr) *ast.Ident {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t
+	case *ast.SelectorExpr:
+		return t.Sel
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	}
+	return ast.NewIdent("")
+}
+
+func isByteSlice(t *ast.ArrayType) bool {
+	ident, ok := t.Elt.(*ast.Ident)
+	return ok && t.Len == nil && (ident.Name == "byte" || ident.Name == "uint8")
+}
+
+// go2apibMain writes the Data Structures of the Go package named by the
+// arguments to stdout. The directory is given by -dir or as the argument.
+func go2apibMain(args []string) {
+	fs := flag.NewFlagSet("go2apib", flag.ExitOnError)
+	var dir string
+	fs.StringVar(&dir, "dir", ".", "Go package directory, also accepted as the argument.")
+	fs.Parse(args)
+
+	switch {
+	case fs.NArg() > 1:
+		fs.Usage()
+		os.Exit(1)
+	case fs.NArg() == 1:
+		dir = fs.Arg(0)
+	}
+
+	dss, err := ReadGoPackage(dir)
+	if err == nil {
+		err = NewBlueprintWriter(os.Stdout).WriteDataStructures(dss)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,200 @@
+package main_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+// readGo writes src to a package in a temporary directory and reads it back
+// as MSON, which must parse.
+func readGo(t *testing.T, src string) string {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "models.go"), []byte(src), 0644); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	dss, err := ReadGoPackage(dir)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewBlueprintWriter(&buf).WriteDataStructures(dss); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	if _, err := Parse("models.apib", "# API\n"+buf.String()); err != nil {
+		t.Fatalf("err = %v, want nil for:\n%v", err, buf.String())
+	}
+	return buf.String()
+}
+
+func Test_ReadGoPackage_should_read_structs(t *testing.T) {
+	t.Parallel()
+
+	actual := readGo(t, `package fruit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Produce is something to eat.
+type Produce struct {
+	Base
+	// What colour is it?
+	Colour   string            `+"`json:\"colour\"`"+`
+	Weight   json.Number       `+"`json:\"weight,omitempty\"`"+`
+	Parent   *Produce          `+"`json:\"parent\"`"+`
+	Seeds    []*Seed           `+"`json:\"seeds,omitempty\"`"+`
+	Labels   map[string]string `+"`json:\"labels,omitempty\"`"+`
+	Picked   time.Time         `+"`json:\"picked_at\"`"+`
+	Grade    Grade             `+"`json:\"grade\"`"+` // Quality.
+	Internal string            `+"`json:\"-\"`"+`
+	Count    int
+	secret   string
+	Extra
+}
+
+type Grade string
+
+const (
+	// GradePremium is the best.
+	GradePremium Grade = "premium"
+	GradeStandard Grade = "standard"
+)
+
+type Crate []Produce
+
+type Base struct {
+	ID string `+"`json:\"id\"`"+`
+}
+
+type Seed struct {
+	Weight *float64 `+"`json:\"weight,omitempty\"`"+`
+}
+
+type Extra struct {
+	Note string `+"`json:\"note,omitempty\"`"+`
+}
+
+type Picker interface {
+	Pick() Produce
+}
+
+type unexported struct{}
+`)
+
+	expected := `## Data Structures
+
+### Produce (Base)
+Something to eat.
+
++ colour (string, required) - What colour is it?
++ weight (number)
++ parent (Produce, nullable)
++ seeds (array[Seed])
++ labels (object)
++ picked_at (string, required)
++ grade (Grade, required) - Quality.
++ Count (number, required)
++ Include Extra
+
+### Grade (enum[string])
++ premium - The best.
++ standard
+
+### Crate (array[Produce])
+
+### Base
++ id (string, required)
+
+### Seed
++ weight (number)
+
+### Extra
++ note (string)
+`
+
+	if actual != expected {
+		t.Errorf("ReadGoPackage() =\n%v\nwant:\n%v", actual, expected)
+	}
+}
+
+func Test_ReadGoPackage_should_read_types_of_other_packages_as_objects(t *testing.T) {
+	t.Parallel()
+
+	actual := readGo(t, `package fruit
+
+import (
+	"net/http"
+	"net/url"
+)
+
+type Picker struct {
+	http.Client
+	*url.URL    `+"`json:\"url\"`"+`
+	Client  *http.Client  `+"`json:\"client,omitempty\"`"+`
+	Cookies []http.Cookie `+"`json:\"cookies\"`"+`
+}
+`)
+
+	expected := `## Data Structures
+
+### Picker
++ url (object, nullable)
++ client (object)
++ cookies (array[object], required)
+`
+
+	if actual != expected {
+		t.Errorf("ReadGoPackage() =\n%v\nwant:\n%v", actual, expected)
+	}
+}
+
+func Test_ReadGoPackage_should_round_trip_GoWriter_output(t *testing.T) {
+	t.Parallel()
+
+	// required numbers are Go strings, they read back as strings.
+	src := `# Fruit API
+## Data Structures
+
+### Dimension
+Size of the produce.
+
++ radius (number) - In centimetres.
++ length (number)
+
+### Produce (Dimension)
++ colour (string, required) - What colour is it?
++ tags (array[string], required)
++ grade (Grade)
++ parent (Produce, nullable)
+
+### Grade (enum[string])
++ premium - The best.
++ standard
+`
+
+	doc, err := Parse("fruit.apib", src)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var expected, gosrc bytes.Buffer
+	if err := NewBlueprintWriter(&expected).WriteDataStructures(doc.DataStructures); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	if err := NewGoWriter(&gosrc, "fruit").WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	actual := readGo(t, gosrc.String())
+	if actual != expected.String() {
+		t.Errorf("ReadGoPackage() =\n%v\nwant:\n%v\nfrom:\n%v", actual, expected.String(), gosrc.String())
+	}
+}
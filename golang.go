@@ -1,16 +1,39 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
+	"go/format"
 	"io"
+	"sort"
 	"strings"
+	"unicode"
 )
 
 type GoWriter struct {
 	io.Writer
+	// Client adds a Client with a method per action to the generated source.
+	Client bool
+	// Server adds a Server interface with a method per action and a handler
+	// routing requests to it.
+	Server bool
+
 	pkgname string
+	// doc is the document being written, used to expand includes.
+	doc *Document
+	// imports used by the generated source keyed by path with the name as value.
+	imports map[string]string
+}
+
+func NewGoWriter(w io.Writer, pkgname string) *GoWriter {
+	return &GoWriter{Writer: w, pkgname: pkgname}
 }
 
+const (
+	primitivesImport = "github.com/nfisher/apib2go/primitives"
+	apibImport       = "github.com/nfisher/apib2go/apib"
+)
+
 type b []byte
 
 func bs(format string, args ...interface{}) []byte {
@@ -18,23 +41,530 @@ func bs(format string, args ...interface{}) []byte {
 	return b(s)
 }
 
-func (w *GoWriter) WriteDoc(doc *Document) {
+// primitives maps the APIB base types to their optional and required Go types.
+var primitives = map[string][2]string{
+	"string":  {"String", "string"},
+	"number":  {"Number", "string"},
+	"boolean": {"Boolean", "bool"},
+}
+
+// WriteDoc writes the data structures of doc as gofmt formatted Go source,
+// followed by a Client and Server for the actions when they are set.
+// An error is returned when the generated source does not parse.
+func (w *GoWriter) WriteDoc(doc *Document) error {
+	return w.writeFile(doc, true, func() {
+		w.writeTypes(doc)
+		if w.Client || w.Server {
+			endpoints := w.writeEndpointTypes(doc)
+			if w.Client {
+				w.writeClient(doc, endpoints)
+			}
+			if w.Server {
+				w.writeServer(doc, endpoints)
+			}
+		}
+	})
+}
+
+// writeFile writes the package clause and imports of doc followed by the
+// source written by body, formatted with gofmt. pkgDoc adds the package
+// documentation.
+func (w *GoWriter) writeFile(doc *Document, pkgDoc bool, body func()) error {
+	w.doc = doc
+	w.imports = map[string]string{}
+
+	// the imports are known once the body is written.
+	out := w.Writer
+	var buf, src bytes.Buffer
+	w.Writer = &buf
+	body()
+	w.Writer = &src
+
+	if pkgDoc && doc.Title != "" {
+		w.writeComment("", fmt.Sprintf("Package %v is generated from the %v blueprint.", w.pkgname, doc.Title))
+		if doc.Overview != "" {
+			w.Write(bs("//\n"))
+			w.writeComment("", doc.Overview)
+		}
+	}
 	w.Write(bs("package %v\n\n", w.pkgname))
-	w.Write(bs("import . \"github.com/nfisher/apib2go/primitives\"\n\n"))
 
+	paths := make([]string, 0, len(w.imports))
+	for path := range w.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	if len(paths) > 0 {
+		w.Write(bs("import (\n"))
+		for _, path := range paths {
+			w.Write(bs("\t%v%q\n", w.imports[path], path))
+		}
+		w.Write(bs(")\n\n"))
+	}
+
+	w.Write(buf.Bytes())
+	w.Writer = out
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated source for package %v is invalid: %v", w.pkgname, err)
+	}
+
+	_, err = w.Write(formatted)
+	return err
+}
+
+// writeComment writes text as a line comment at indent.
+func (w *GoWriter) writeComment(indent, text string) {
+	if text == "" {
+		return
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			w.Write(bs("%v//\n", indent))
+			continue
+		}
+		w.Write(bs("%v// %v\n", indent, line))
+	}
+}
+
+// writeDocComment writes text as the doc comment of the identifier name,
+// which starts the comment as godoc expects. A noun phrase follows the name
+// as in Produce is a fruit, other text as in Fruit: Is it fruit?
+func (w *GoWriter) writeDocComment(indent, name, text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(text, name+" "):
+	case isNounPhrase(text):
+		text = name + " is " + lowerFirst(text)
+	default:
+		text = name + ": " + text
+	}
+	w.writeComment(indent, text)
+}
+
+// determiners start the noun phrases of descriptions.
+var determiners = map[string]bool{
+	"a": true, "an": true, "the": true, "any": true, "anything": true,
+	"each": true, "every": true, "one": true, "some": true, "something": true,
+}
+
+// isNounPhrase reports whether text starts with a determiner and is not a
+// question.
+func isNounPhrase(text string) bool {
+	word := strings.ToLower(strings.Fields(text)[0])
+	return determiners[word] && !strings.HasSuffix(text, "?")
+}
+
+// lowerFirst returns s with its first letter in lower case unless it starts
+// an initialism such as URL.
+func lowerFirst(s string) string {
+	r := []rune(s)
+	if len(r) > 1 && unicode.IsUpper(r[1]) {
+		return s
+	}
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// use records an import required by the generated source, name is the
+// import name followed by a space or empty.
+func (w *GoWriter) use(path, name string) {
+	w.imports[path] = name
+}
+
+func (w *GoWriter) writeTypes(doc *Document) {
 	for _, model := range doc.DataStructures {
-		w.Write(bs("type %s struct {\n", model.Name))
-		for _, property := range model.Properties {
-			pre := "*"
-			if strings.Contains("string number boolean ", property.Type) {
-				pre = ""
+		_, primitive := primitives[model.Type]
+		name := GoName(model.Name)
+		w.writeDocComment("", name, model.Description)
+
+		switch {
+		case model.IsTypeEnum():
+			w.writeStruct(name, "", typeEnumOneOf(model.Properties))
+
+		case model.IsEnum:
+			w.writeEnum(name, model.Properties)
+
+		case model.IsArray:
+			w.Write(bs("type %s %s\n\n", name, w.goType(name, &Property{Type: model.Type, IsArray: true})))
+
+		case primitive:
+			w.Write(bs("type %s %s\n\n", name, w.goType(name, &Property{Type: model.Type, Required: true})))
+
+		default:
+			w.writeStruct(name, model.Base(), model.Properties)
+		}
+	}
+}
+
+// writeStruct writes the struct name followed by the types synthesised for
+// its inline object properties, e.g. ProduceDimensions. A base type is
+// embedded so its fields are flattened into the JSON object.
+func (w *GoWriter) writeStruct(name, base string, properties []*Property) {
+	properties = w.doc.Expand(properties)
+
+	var oneOfs []*Property
+	var oneOfFields []string
+	var own []string
+	fields := goNames{}
+
+	w.Write(bs("type %s struct {\n", name))
+	embedded := ""
+	if base != "" {
+		embedded = fields.unique(GoName(base))
+		w.Write(bs("\t%v\n", embedded))
+	}
+	for _, property := range properties {
+		if property.OneOf {
+			oneOfs = append(oneOfs, property)
+			field := oneOfField(len(oneOfs))
+			oneOfFields = append(oneOfFields, fields.unique(field))
+			w.Write(bs("\t%v %v `json:\"-\"`\n", oneOfFields[len(oneOfFields)-1], name+field))
+			continue
+		}
+		field := fields.unique(GoName(property.Name))
+		w.writeDocComment("\t", field, property.Description)
+		own = append(own, fmt.Sprintf("%v %v `json:\"%v\"`", field, w.goType(name, property), jsonTag(property)))
+		w.Write(bs("\t%v\n", own[len(own)-1]))
+	}
+	w.Write(bs("}\n\n"))
+
+	for _, property := range properties {
+		switch {
+		case property.IsInline():
+			w.writeStruct(inlineName(name, property), "", property.Properties)
+		case property.IsTypeEnum():
+			w.writeStruct(inlineName(name, property), "", typeEnumOneOf(property.Properties))
+		case property.IsInlineEnum():
+			w.writeEnum(inlineName(name, property), property.Properties)
+		}
+	}
+
+	if len(oneOfs) > 0 {
+		w.writeOneOfs(name, oneOfs)
+	}
+
+	switch {
+	case base != "" && w.hasJSONMethods(base, map[string]bool{}):
+		w.writeDerivedJSONMethods(name, embedded, own, oneOfs, oneOfFields)
+	case len(oneOfs) > 0:
+		w.writeJSONMethods(name, oneOfs, oneOfFields)
+	}
+}
+
+// hasJSONMethods reports whether the struct written for the data structure
+// name has JSON methods, which are promoted to the structs embedding it.
+func (w *GoWriter) hasJSONMethods(name string, seen map[string]bool) bool {
+	ds := w.doc.Lookup(name)
+	if ds == nil || seen[ds.Name] {
+		return false
+	}
+	seen[ds.Name] = true
+
+	if ds.IsTypeEnum() {
+		return true
+	}
+	if ds.IsEnum || ds.IsArray || isPrimitive(ds.Type) {
+		return false
+	}
+	for _, property := range w.doc.Expand(ds.Properties) {
+		if property.OneOf {
+			return true
+		}
+	}
+	return ds.Base() != "" && w.hasJSONMethods(ds.Base(), seen)
+}
+
+// oneOfField is the name of the field holding the nth One Of of a struct.
+func oneOfField(n int) string {
+	if n == 1 {
+		return "OneOf"
+	}
+	return fmt.Sprintf("OneOf%v", n)
+}
+
+// typeEnumOneOf converts the members of an enum of named types into a One Of
+// where each alternative includes the members of one type.
+func typeEnumOneOf(members []*Property) []*Property {
+	oneOf := &Property{OneOf: true}
+	for _, member := range members {
+		alt := &Property{Properties: []*Property{{Include: member.Type}}}
+		oneOf.Properties = append(oneOf.Properties, alt)
+	}
+	return []*Property{oneOf}
+}
+
+// writeOneOfs writes a sealed interface for each One Of in the struct name
+// and a struct per alternative.
+func (w *GoWriter) writeOneOfs(name string, oneOfs []*Property) {
+	for i, oneOf := range oneOfs {
+		iface := name + oneOfField(i+1)
+
+		w.Write(bs("// %v is implemented by the alternatives of a One Of in %v.\n", iface, name))
+		w.Write(bs("type %v interface {\n", iface))
+		w.Write(bs("\tis%v()\n", iface))
+		w.Write(bs("}\n\n"))
+
+		for _, alt := range oneOf.Properties {
+			altName := alternativeName(w.doc, name, alt)
+			w.writeStruct(altName, "", alternativeMembers(alt))
+			w.Write(bs("func (%v) is%v() {}\n\n", altName, iface))
+		}
+	}
+}
+
+// writeJSONMethods writes JSON methods for the struct name that flatten the
+// chosen alternative of each One Of into its object. oneOfFields are the
+// names of the struct fields holding each One Of.
+func (w *GoWriter) writeJSONMethods(name string, oneOfs []*Property, oneOfFields []string) {
+	w.use("encoding/json", "")
+	w.use(apibImport, "")
+
+	fields := make([]string, 0, len(oneOfs))
+	for _, field := range oneOfFields {
+		fields = append(fields, "v."+field)
+	}
+
+	w.Write(bs("func (v %v) MarshalJSON() ([]byte, error) {\n", name))
+	w.Write(bs("\ttype plain %v\n", name))
+	w.Write(bs("\treturn apib.MarshalObjects(plain(v), %v)\n", strings.Join(fields, ", ")))
+	w.Write(bs("}\n\n"))
+
+	w.Write(bs("func (v *%v) UnmarshalJSON(b []byte) error {\n", name))
+	w.Write(bs("\ttype plain %v\n", name))
+	w.Write(bs("\tif err := json.Unmarshal(b, (*plain)(v)); err != nil {\n"))
+	w.Write(bs("\t\treturn err\n"))
+	w.Write(bs("\t}\n"))
+	w.writeUnmarshalOneOfs(name, oneOfs, fields)
+	w.Write(bs("\n\treturn nil\n"))
+	w.Write(bs("}\n\n"))
+}
+
+// writeDerivedJSONMethods writes JSON methods for the struct name embedding
+// the base field, whose JSON methods would otherwise be promoted and drop
+// the own fields of name. The base and the own fields are merged as separate
+// objects with the chosen alternatives of the One Ofs of name.
+func (w *GoWriter) writeDerivedJSONMethods(name, base string, own []string, oneOfs []*Property, oneOfFields []string) {
+	w.use("encoding/json", "")
+	w.use(apibImport, "")
+
+	ownFields := make([]string, 0, len(own))
+	for _, decl := range own {
+		ownFields = append(ownFields, strings.Fields(decl)[0])
+	}
+
+	writeOwn := func() {
+		w.Write(bs("\ttype own struct {\n"))
+		for _, decl := range own {
+			w.Write(bs("\t\t%v\n", decl))
+		}
+		w.Write(bs("\t}\n"))
+	}
+
+	values := []string{"v." + base}
+	if len(own) > 0 {
+		ownValues := make([]string, 0, len(own))
+		for _, field := range ownFields {
+			ownValues = append(ownValues, "v."+field)
+		}
+		values = append(values, "own{"+strings.Join(ownValues, ", ")+"}")
+	}
+	fields := make([]string, 0, len(oneOfs))
+	for _, field := range oneOfFields {
+		fields = append(fields, "v."+field)
+	}
+
+	w.Write(bs("func (v %v) MarshalJSON() ([]byte, error) {\n", name))
+	if len(own) > 0 {
+		writeOwn()
+	}
+	w.Write(bs("\treturn apib.MarshalObjects(%v)\n", strings.Join(append(values, fields...), ", ")))
+	w.Write(bs("}\n\n"))
+
+	w.Write(bs("func (v *%v) UnmarshalJSON(b []byte) error {\n", name))
+	w.Write(bs("\tif err := json.Unmarshal(b, &v.%v); err != nil {\n", base))
+	w.Write(bs("\t\treturn err\n"))
+	w.Write(bs("\t}\n"))
+	if len(own) > 0 {
+		w.Write(bs("\n"))
+		writeOwn()
+		w.Write(bs("\tvar o own\n"))
+		w.Write(bs("\tif err := json.Unmarshal(b, &o); err != nil {\n"))
+		w.Write(bs("\t\treturn err\n"))
+		w.Write(bs("\t}\n"))
+		for _, field := range ownFields {
+			w.Write(bs("\tv.%v = o.%v\n", field, field))
+		}
+	}
+	w.writeUnmarshalOneOfs(name, oneOfs, fields)
+	w.Write(bs("\n\treturn nil\n"))
+	w.Write(bs("}\n\n"))
+}
+
+// writeUnmarshalOneOfs writes the statements of an UnmarshalJSON method that
+// decode b into the alternative of each One Of identified by its keys.
+// fields are the expressions holding each One Of.
+func (w *GoWriter) writeUnmarshalOneOfs(name string, oneOfs []*Property, fields []string) {
+	if len(oneOfs) == 0 {
+		return
+	}
+
+	w.Write(bs("\n\tkeys, err := apib.Keys(b)\n"))
+	w.Write(bs("\tif err != nil {\n"))
+	w.Write(bs("\t\treturn err\n"))
+	w.Write(bs("\t}\n"))
+
+	for i, oneOf := range oneOfs {
+		w.Write(bs("\n\tswitch {\n"))
+		for _, alt := range oneOf.Properties {
+			var conds []string
+			for _, key := range w.alternativeKeys(alt) {
+				conds = append(conds, fmt.Sprintf("keys[%q]", key))
 			}
-			f := "  %v %v%v `json:\"%v,omitempty\"`\n"
-			if property.IsArray {
-				f = "  %v []%v%v `json:\"%v,omitempty\"`\n"
+			if len(conds) == 0 {
+				continue
 			}
-			w.Write(bs(f, strings.Title(property.Name), pre, strings.Title(property.Type), property.Name))
+
+			w.Write(bs("\tcase %v:\n", strings.Join(conds, ", ")))
+			w.Write(bs("\t\tvar alt %v\n", alternativeName(w.doc, name, alt)))
+			w.Write(bs("\t\tif err := json.Unmarshal(b, &alt); err != nil {\n"))
+			w.Write(bs("\t\t\treturn err\n"))
+			w.Write(bs("\t\t}\n"))
+			w.Write(bs("\t\t%v = alt\n", fields[i]))
 		}
-		w.Write(bs("}\n\n"))
+		w.Write(bs("\t}\n"))
+	}
+}
+
+// alternativeName is the type name for an alternative of a One Of in parent.
+// A single property is named after the property, a group after its included
+// type or the names of its members.
+func alternativeName(doc *Document, parent string, alt *Property) string {
+	if alt.Name != "" {
+		return parent + GoName(alt.Name)
+	}
+
+	if len(alt.Properties) == 1 && alt.Properties[0].Include != "" {
+		return parent + GoName(alt.Properties[0].Include)
+	}
+
+	name := parent
+	for _, property := range doc.Expand(alt.Properties) {
+		name += GoName(property.Name)
+	}
+	return name
+}
+
+// alternativeMembers returns the struct members of an alternative.
+func alternativeMembers(alt *Property) []*Property {
+	if alt.Name != "" {
+		return []*Property{alt}
+	}
+	return alt.Properties
+}
+
+// alternativeKeys returns the JSON members that identify an alternative.
+func (w *GoWriter) alternativeKeys(alt *Property) []string {
+	var keys []string
+	for _, property := range w.doc.Expand(alternativeMembers(alt)) {
+		if property.Name != "" {
+			keys = append(keys, property.Name)
+		}
+	}
+	return keys
+}
+
+// writeEnum writes a string type name with a constant for each member.
+func (w *GoWriter) writeEnum(name string, members []*Property) {
+	members = w.doc.Expand(members)
+
+	names := goNames{}
+	consts := make([]string, 0, len(members))
+	for _, member := range members {
+		consts = append(consts, names.unique(name+GoName(member.Name)))
+	}
+
+	w.Write(bs("type %s string\n\n", name))
+
+	w.Write(bs("const (\n"))
+	for i, member := range members {
+		w.writeDocComment("\t", consts[i], member.Description)
+		w.Write(bs("\t%v %v = %q\n", consts[i], name, member.Name))
+	}
+	w.Write(bs(")\n\n"))
+
+	w.Write(bs("// Values returns every member of %v.\n", name))
+	w.Write(bs("func (%v) Values() []%v {\n", name, name))
+	w.Write(bs("\treturn []%v{%v}\n", name, strings.Join(consts, ", ")))
+	w.Write(bs("}\n\n"))
+
+	w.Write(bs("// IsValid reports whether v is a member of %v.\n", name))
+	w.Write(bs("func (v %v) IsValid() bool {\n", name))
+	if len(consts) > 0 {
+		w.Write(bs("\tswitch v {\n"))
+		w.Write(bs("\tcase %v:\n", strings.Join(consts, ", ")))
+		w.Write(bs("\t\treturn true\n"))
+		w.Write(bs("\t}\n"))
+	}
+	w.Write(bs("\treturn false\n"))
+	w.Write(bs("}\n\n"))
+}
+
+// inlineName is the type name synthesised for an inline object property.
+func inlineName(parent string, property *Property) string {
+	return parent + GoName(property.Name)
+}
+
+// goType returns the Go type for property of the type parent. Optional
+// properties are pointers, required properties are instances unless they are
+// also nullable. Array members keep the optional representation, attributes
+// apply to the array.
+func (w *GoWriter) goType(parent string, property *Property) string {
+	t := property.Type
+	if t == "" {
+		t = "string"
+	}
+
+	instance := property.Required && !property.Nullable && !property.IsArray
+
+	inline := property.IsInline() || property.IsInlineEnum() || property.IsTypeEnum()
+
+	// the name of a synthesised type is already a Go name.
+	s := GoName(t)
+	if inline {
+		s = inlineName(parent, property)
+	}
+	if p, ok := primitives[t]; ok && !inline {
+		s = p[0]
+		if instance {
+			s = p[1]
+		} else {
+			w.use(primitivesImport, ". ")
+		}
+	} else if !instance {
+		s = "*" + s
+	}
+
+	if property.IsArray {
+		return "[]" + s
+	}
+
+	return s
+}
+
+// jsonTag returns the json struct tag value for property. Only optional
+// properties omit empty values so required and nullable fields round-trip.
+func jsonTag(property *Property) string {
+	if property.Required || property.Nullable {
+		return property.Name
 	}
+	return property.Name + ",omitempty"
 }
@@ -0,0 +1,485 @@
+package main_test
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+func writeGo(t *testing.T, input string) string {
+	doc, err := Parse("fruits.apib", input)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	err = NewGoWriter(&buf, "fruit").WriteDoc(doc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	return buf.String()
+}
+
+// goRun writes files to a package main in a temporary directory and returns
+// the output of go run, the test is skipped without a go command.
+func goRun(t *testing.T, files map[string]string) string {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+
+	dir := t.TempDir()
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+	}
+
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("err = %v, want nil:\n%s", err, out)
+	}
+	return string(out)
+}
+
+// containsGo reports whether src contains e ignoring differences in WS, the
+// output is gofmt aligned.
+func containsGo(src, e string) bool {
+	return strings.Contains(strings.Join(strings.Fields(src), " "), strings.Join(strings.Fields(e), " "))
+}
+
+func Test_GoWriter_WriteDoc_should_honour_type_attributes(t *testing.T) {
+	t.Parallel()
+
+	src := writeGo(t, `# API
+## Data Structures
+### Produce
++ colour (string)
++ name (string, required)
++ weight (number, required)
++ fruit (boolean, required)
++ dimensions (Dimension)
++ size (Dimension, required)
++ parent (Produce, nullable)
++ seeds (array[number], required)
++ label (string, required, nullable)`)
+
+	expected := []string{
+		"Colour String `json:\"colour,omitempty\"`",
+		"Name string `json:\"name\"`",
+		"Weight string `json:\"weight\"`",
+		"Fruit bool `json:\"fruit\"`",
+		"Dimensions *Dimension `json:\"dimensions,omitempty\"`",
+		"Size Dimension `json:\"size\"`",
+		"Parent *Produce `json:\"parent\"`",
+		"Seeds []Number `json:\"seeds\"`",
+		"Label String `json:\"label\"`",
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_write_numbers_as_strings(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("produce.apib", `# API
+## Data Structures
+### Produce
++ weight (number, required)
++ price (number)`)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewGoWriter(&buf, "main").WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	actual := goRun(t, map[string]string{
+		"produce.go": buf.String(),
+		"main.go": `package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+func main() {
+	b, err := json.Marshal(Produce{Weight: "1.25", Price: apib.Number("0.1")})
+	fmt.Println(string(b), err)
+
+	var p Produce
+	err = json.Unmarshal([]byte(` + "`" + `{"weight":"12345678901234567890","price":"2"}` + "`" + `), &p)
+	fmt.Println(p.Weight, *p.Price, err)
+}
+`,
+	})
+
+	expected := "{\"weight\":\"1.25\",\"price\":\"0.1\"} <nil>\n12345678901234567890 2 <nil>\n"
+	if actual != expected {
+		t.Errorf("got %q, want %q", actual, expected)
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_synthesise_inline_types(t *testing.T) {
+	t.Parallel()
+
+	src := writeGo(t, `# API
+## Data Structures
+### Produce
++ dimensions (object, required)
+    + radius (number)
+    + core (object)
+        + size (number)
++ seeds (array[object])
+    + weight (number)`)
+
+	expected := []string{
+		"Dimensions ProduceDimensions `json:\"dimensions\"`",
+		"Seeds []*ProduceSeeds `json:\"seeds,omitempty\"`",
+		"type ProduceDimensions struct {",
+		"Core *ProduceDimensionsCore `json:\"core,omitempty\"`",
+		"type ProduceDimensionsCore struct {",
+		"Size Number `json:\"size,omitempty\"`",
+		"type ProduceSeeds struct {",
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_write_enums(t *testing.T) {
+	t.Parallel()
+
+	src := writeGo(t, `# API
+## Data Structures
+### Status (enum[string])
++ Members
+    + active
+    + inactive
+
+### Produce
++ status (Status, required)
++ ripeness (enum[string])
+    + green
+    + ripe`)
+
+	expected := []string{
+		"type Status string",
+		"StatusActive Status = \"active\"",
+		"func (Status) Values() []Status {",
+		"return []Status{StatusActive, StatusInactive}",
+		"func (v Status) IsValid() bool {",
+		"case StatusActive, StatusInactive:",
+		"Status Status `json:\"status\"`",
+		"Ripeness *ProduceRipeness `json:\"ripeness,omitempty\"`",
+		"type ProduceRipeness string",
+		"ProduceRipenessRipe ProduceRipeness = \"ripe\"",
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_embed_base_types(t *testing.T) {
+	t.Parallel()
+
+	src := writeGo(t, `# API
+## Data Structures
+### User (object)
++ name (string)
+
+### AdminUser (User)
++ role (string)
+
+### Email (string)
+
+### Users (array[User])`)
+
+	expected := []string{
+		"type User struct {",
+		"type AdminUser struct {\n  User\n  Role String `json:\"role,omitempty\"`\n}",
+		"type Email string\n",
+		"type Users []*User\n",
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_expand_includes(t *testing.T) {
+	t.Parallel()
+
+	src := writeGo(t, `# API
+## Data Structures
+### Timestamps
++ created (string)
+
+### Produce
++ Include Timestamps
++ name (string)`)
+
+	expected := "type Produce struct {\n  Created String `json:\"created,omitempty\"`\n  Name String `json:\"name,omitempty\"`\n}"
+	if !containsGo(src, expected) {
+		t.Errorf("missing %q in:\n%v", expected, src)
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_write_one_of_sum_types(t *testing.T) {
+	t.Parallel()
+
+	src := writeGo(t, `# API
+## Data Structures
+### Card
++ number (string)
+
+### Payment
++ amount (number)
++ One Of
+    + card (Card)
+    + Properties
+        + iban (string)
+        + bic (string)
+
+### Method (enum)
++ (Card)`)
+
+	expected := []string{
+		"\"encoding/json\"",
+		"\"github.com/nfisher/apib2go/apib\"",
+		"OneOf PaymentOneOf `json:\"-\"`",
+		"type PaymentOneOf interface {\n  isPaymentOneOf()\n}",
+		"type PaymentCard struct {\n  Card *Card `json:\"card,omitempty\"`\n}",
+		"func (PaymentCard) isPaymentOneOf() {}",
+		"type PaymentIbanBic struct {",
+		"func (PaymentIbanBic) isPaymentOneOf() {}",
+		"return apib.MarshalObjects(plain(v), v.OneOf)",
+		"case keys[\"iban\"], keys[\"bic\"]:\n    var alt PaymentIbanBic",
+		"type Method struct {\n  OneOf MethodOneOf `json:\"-\"`\n}",
+		"type MethodCard struct {\n  Number String `json:\"number,omitempty\"`\n}",
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_marshal_types_derived_from_one_of(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("orders.apib", `# API
+## Data Structures
+### Order
++ id (string)
++ One Of
+    + card (string)
+    + iban (string)
+
+### AdminOrder (Order)
++ admin (boolean)
+
+### AuditOrder (AdminOrder)
++ auditor (string)`)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewGoWriter(&buf, "main").WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	actual := goRun(t, map[string]string{
+		"orders.go": buf.String(),
+		"main.go": `package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+func main() {
+	admin := AdminOrder{Order{ID: apib.String("1"), OneOf: OrderIban{Iban: apib.String("DE")}}, apib.Boolean(true)}
+	b, err := json.Marshal(admin)
+	fmt.Println(string(b), err)
+
+	var audit AuditOrder
+	err = json.Unmarshal([]byte(` + "`" + `{"id":"2","card":"4111","admin":false,"auditor":"ann"}` + "`" + `), &audit)
+	fmt.Println(*audit.ID, *audit.OneOf.(OrderCard).Card, *audit.Admin, *audit.Auditor, err)
+}
+`,
+	})
+
+	expected := "{\"id\":\"1\",\"iban\":\"DE\",\"admin\":true} <nil>\n2 4111 false ann <nil>\n"
+	if actual != expected {
+		t.Errorf("got %q, want %q", actual, expected)
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_write_doc_comments(t *testing.T) {
+	t.Parallel()
+
+	src := writeGo(t, `# Fruit API
+Fruit distribution API.
+
+## Data Structures
+
+### Produce
+Anything grown for eating.
+
++ colour (string) - The colour of the skin.
++ url (string) - An image of it.
++ status (enum) - URL encoded.
+    + ripe - Ready to eat.
+    + rotten - ProduceStatusRotten means it is too late.
++ fruit (boolean) - Is it fruit?
++ grade (string) - The best? Or the rest?`)
+
+	expected := []string{
+		"// Package fruit is generated from the Fruit API blueprint.\n//\n// Fruit distribution API.\npackage fruit\n",
+		"// Produce is anything grown for eating.\ntype Produce struct {\n",
+		"  // Colour is the colour of the skin.\n  Colour String",
+		"  // URL is an image of it.\n  URL String",
+		"  // Status: URL encoded.\n  Status *ProduceStatus",
+		"  // Fruit: Is it fruit?\n  Fruit Boolean",
+		"  // Grade: The best? Or the rest?\n  Grade String",
+		"  // ProduceStatusRipe: Ready to eat.\n  ProduceStatusRipe ProduceStatus",
+		"  // ProduceStatusRotten means it is too late.\n  ProduceStatusRotten ProduceStatus",
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_write_gofmt_source(t *testing.T) {
+	t.Parallel()
+
+	src := writeGo(t, `# API
+## Data Structures
+### Produce
++ colour (string)
++ dimensions (Dimension, required)`)
+
+	formatted, err := format.Source([]byte(src))
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	if string(formatted) != src {
+		t.Errorf("src is not gofmt formatted:\n%v", src)
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_reject_invalid_source(t *testing.T) {
+	t.Parallel()
+
+	doc, _ := Parse("fruits.apib", "# API\n## Data Structures\n### Produce\n+ colour (string)")
+
+	var buf bytes.Buffer
+	err := NewGoWriter(&buf, "fruit salad").WriteDoc(doc)
+	if err == nil {
+		t.Errorf("err = nil, want invalid source error")
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("buf = %q, want nothing written", buf.String())
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_sanitise_names(t *testing.T) {
+	t.Parallel()
+
+	src := writeGo(t, "# API\n## Data Structures\n### user_account\n+ user_id (string)\n+ userId (string)\n+ 2fa (boolean)\n+ `first name` (string)\n+ status (enum)\n    + in-progress\n")
+
+	expected := []string{
+		"type UserAccount struct {",
+		"UserID String `json:\"user_id,omitempty\"`",
+		"UserID2 String `json:\"userId,omitempty\"`",
+		"X2fa Boolean `json:\"2fa,omitempty\"`",
+		"FirstName String `json:\"first name,omitempty\"`",
+		"Status *UserAccountStatus `json:\"status,omitempty\"`",
+		"UserAccountStatusInProgress UserAccountStatus = \"in-progress\"",
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+}
+
+func Test_GoWriter_WriteDoc_should_compile_inline_types_of_initialisms(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("api.apib", `# API
+## Data Structures
+### API
++ v (object)
+    + id (string)
++ x (enum)
+    + a
++ y (enum)
+    + (A)
+
+### A
++ x (object)
+    + id (string)`)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewGoWriter(&buf, "main").WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	src := buf.String()
+
+	expected := []string{
+		"V *APIV `json:\"v,omitempty\"`",
+		"X *APIX `json:\"x,omitempty\"`",
+		"Y *APIY `json:\"y,omitempty\"`",
+		"X *AX `json:\"x,omitempty\"`",
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+
+	goRun(t, map[string]string{
+		"api.go":  src,
+		"main.go": "package main\n\nfunc main() {}\n",
+	})
+}
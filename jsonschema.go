@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+)
+
+const draft2020URI = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchemaWriter writes data structures as JSON Schema (draft 2020-12)
+// documents. Models reference each other by their schemaFile name.
+type JSONSchemaWriter struct {
+	io.Writer
+}
+
+func NewJSONSchemaWriter(w io.Writer) *JSONSchemaWriter {
+	return &JSONSchemaWriter{Writer: w}
+}
+
+// WriteDoc writes the schema of every data structure in doc, one after the
+// other.
+func (w *JSONSchemaWriter) WriteDoc(doc *Document) error {
+	for _, ds := range doc.DataStructures {
+		if err := w.WriteSchema(doc, ds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteSchema writes the schema of ds, a data structure of doc.
+func (w *JSONSchemaWriter) WriteSchema(doc *Document, ds *DataStructure) error {
+	b := &schemaBuilder{doc: doc, refURI: schemaFile}
+	s := b.dataStructure(ds)
+	draft2020(s)
+	s.Schema = draft2020URI
+	s.ID = schemaFile(ds.Name)
+	s.Title = ds.Name
+	s.Description = ds.Description
+
+	out, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
+
+// schemaFile returns the file name, and relative URI, of the schema for the
+// data structure name.
+func schemaFile(name string) string {
+	return url.PathEscape(name) + ".json"
+}
+
+// draft2020 rewrites s from the OpenAPI dialect to draft 2020-12. Nullable
+// types accept null and an example becomes examples.
+func draft2020(s *schema) {
+	for _, sub := range [][]*schema{{s.Items}, s.AllOf, s.OneOf, s.AnyOf} {
+		for _, v := range sub {
+			if v != nil {
+				draft2020(v)
+			}
+		}
+	}
+	for _, m := range s.Properties {
+		draft2020(m.Value.(*schema))
+	}
+
+	if s.Example != nil {
+		s.Examples = []interface{}{s.Example}
+		s.Example = nil
+	}
+
+	if !s.Nullable {
+		return
+	}
+	s.Nullable = false
+
+	if t, ok := s.Type.(string); ok && len(s.OneOf) == 0 {
+		s.Type = []string{t, "null"}
+		if s.Enum != nil {
+			s.Enum = append(s.Enum, nil)
+		}
+		return
+	}
+
+	// references and alternatives accept null as another alternative.
+	inner := *s
+	inner.Description, inner.Default, inner.Examples = "", nil, nil
+	alt := &inner
+	if inner.Type == nil && len(inner.AllOf) == 1 && len(inner.OneOf) == 0 {
+		alt = inner.AllOf[0]
+	}
+	*s = schema{
+		Description: s.Description,
+		Default:     s.Default,
+		Examples:    s.Examples,
+		AnyOf:       []*schema{alt, {Type: "null"}},
+	}
+}
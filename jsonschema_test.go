@@ -0,0 +1,106 @@
+package main_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+const schemaDoc = `FORMAT: 1A
+
+# Notes API
+
+# Data Structures
+
+### Note
+Something to remember.
+
++ id: 1 (number, required)
++ parent (Note, nullable) - Parent note.
++ kind (enum[string], nullable)
+    + todo
+    + done
++ tags (array[Tag])
+
+### Tag (enum[string])
++ red
++ green
+`
+
+func Test_JSONSchemaWriter_WriteSchema_should_use_draft_2020_12(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("notes.apib", schemaDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewJSONSchemaWriter(&buf).WriteSchema(doc, doc.DataStructures[0]); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var s interface{}
+	if err := json.Unmarshal(buf.Bytes(), &s); err != nil {
+		t.Fatalf("err = %v, want valid JSON in:\n%v", err, buf.String())
+	}
+
+	// path, expected
+	dataTable := [][]interface{}{
+		{[]interface{}{"$schema"}, "https://json-schema.org/draft/2020-12/schema"},
+		{[]interface{}{"$id"}, "Note.json"},
+		{[]interface{}{"title"}, "Note"},
+		{[]interface{}{"description"}, "Something to remember."},
+		{[]interface{}{"type"}, "object"},
+		{[]interface{}{"required"}, []interface{}{"id"}},
+		{[]interface{}{"properties", "id", "type"}, "number"},
+		{[]interface{}{"properties", "id", "examples"}, []interface{}{float64(1)}},
+		{[]interface{}{"properties", "id", "example"}, nil},
+		{[]interface{}{"properties", "parent", "description"}, "Parent note."},
+		{[]interface{}{"properties", "parent", "anyOf", 0, "$ref"}, "Note.json"},
+		{[]interface{}{"properties", "parent", "anyOf", 1, "type"}, "null"},
+		{[]interface{}{"properties", "parent", "nullable"}, nil},
+		{[]interface{}{"properties", "kind", "type"}, []interface{}{"string", "null"}},
+		{[]interface{}{"properties", "kind", "enum"}, []interface{}{"todo", "done", nil}},
+		{[]interface{}{"properties", "tags", "items", "$ref"}, "Tag.json"},
+	}
+
+	for i, td := range dataTable {
+		actual := lookup(s, td[0].([]interface{})...)
+		if !reflect.DeepEqual(actual, td[1]) {
+			t.Errorf("[%v] %v = %#v, want %#v", i, td[0], actual, td[1])
+		}
+	}
+}
+
+func Test_JSONSchemaWriter_WriteDoc_should_write_each_data_structure(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("notes.apib", schemaDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewJSONSchemaWriter(&buf).WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var ids []interface{}
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var s interface{}
+		if err := dec.Decode(&s); err != nil {
+			t.Fatalf("err = %v, want nil", err)
+		}
+		ids = append(ids, lookup(s, "$id"))
+	}
+
+	expected := []interface{}{"Note.json", "Tag.json"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("ids = %v, want %v", ids, expected)
+	}
+}
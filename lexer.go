@@ -13,9 +13,11 @@ var MetaDelim = "---"
 
 func New(filename, input string) *Lexer {
 	return &Lexer{
-		name:  filename,
-		input: input,
-		Items: make(chan Item, 2),
+		name:   filename,
+		input:  input,
+		Items:  make(chan Item, 2),
+		line:   1,
+		inMeta: true,
 	}
 }
 
@@ -23,6 +25,23 @@ type ItemType int
 type Item struct {
 	Type  ItemType
 	Value string
+	Pos   Position
+}
+
+// Position is a location in a named input. Line and Column are 1-based,
+// Column counts bytes.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+func (p Position) String() string {
+	if p.Line == 0 {
+		return p.Filename
+	}
+	return fmt.Sprintf("%v:%v:%v", p.Filename, p.Line, p.Column)
 }
 
 type Lexer struct {
@@ -32,13 +51,60 @@ type Lexer struct {
 	pos   int
 	width int
 	Items chan Item
+
+	// line bookkeeping for positions, scanned is the offset counted up to.
+	line      int
+	lineStart int
+	scanned   int
+
+	// section flags used to resynchronise after an error.
+	inMeta           bool
+	inDataStructures bool
+	inResource       bool
+	inAttributes     bool
+
+	// indents is the indentation of each open member list.
+	indents []int
+
+	// dsLevel is the header level of the Data Structures section.
+	dsLevel int
+
+	// attrIndent is the indentation of the open attributes section.
+	attrIndent int
 }
 
 func (l *Lexer) Emit(t ItemType) {
-	l.Items <- Item{t, l.input[l.start:l.pos]}
+	l.Items <- Item{t, l.input[l.start:l.pos], l.position(l.start)}
 	l.start = l.pos
 }
 
+// emitSpan emits input[start:end] as an item and continues after end.
+func (l *Lexer) emitSpan(t ItemType, start, end int) {
+	l.start, l.pos = start, end
+	l.Emit(t)
+}
+
+// position computes the line and column of offset.
+func (l *Lexer) position(offset int) Position {
+	if offset < l.scanned {
+		l.line, l.lineStart, l.scanned = 1, 0, 0
+	}
+
+	for ; l.scanned < offset; l.scanned++ {
+		if l.input[l.scanned] == '\n' {
+			l.line++
+			l.lineStart = l.scanned + 1
+		}
+	}
+
+	return Position{
+		Filename: l.name,
+		Offset:   offset,
+		Line:     l.line,
+		Column:   offset - l.lineStart + 1,
+	}
+}
+
 func (l *Lexer) HasPrefix(prefix string) bool {
 	return strings.HasPrefix(l.input[l.start:l.pos], prefix)
 }
@@ -126,12 +192,15 @@ func (l *Lexer) AcceptUntil(stop string) {
 	l.backup()
 }
 
+// Errorf emits an error item and returns the state that resumes lexing after
+// the offending line.
 func (l *Lexer) Errorf(format string, args ...interface{}) StateFn {
 	l.Items <- Item{
 		ItemError,
 		fmt.Sprintf(format, args...),
+		l.position(l.pos),
 	}
-	return nil
+	return LexRecover
 }
 
 func RuneSet(set string) AcceptFn {
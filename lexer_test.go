@@ -141,3 +141,30 @@ func Test_Lexer_Emit(t *testing.T) {
 		t.Errorf("got item.Type = %v, want %v", item.Type, pants)
 	}
 }
+
+func Test_Lexer_Emit_should_record_position(t *testing.T) {
+	l := New("meta.apib", "ab\ncd\n\nef")
+	var pants ItemType = 100
+
+	// skip, expected position
+	dataTable := [][]interface{}{
+		{"", Position{"meta.apib", 0, 1, 1}},
+		{"ab\nc", Position{"meta.apib", 4, 2, 2}},
+		{"d\n\n", Position{"meta.apib", 7, 4, 1}},
+	}
+
+	for i, td := range dataTable {
+		l.AcceptRun(td[0].(string))
+		l.Ignore()
+		l.AcceptRun("abcdef")
+		go func() {
+			l.Emit(pants)
+		}()
+
+		item := <-l.Items
+		expected := td[1].(Position)
+		if item.Pos != expected {
+			t.Errorf("[%v] got item.Pos = %v, want %v", i, item.Pos, expected)
+		}
+	}
+}
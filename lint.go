@@ -0,0 +1,521 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Diagnostic is a problem reported by a lint rule.
+type Diagnostic struct {
+	Pos  Position
+	Rule string
+	Msg  string
+}
+
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%v: %v (%v)", d.Pos, d.Msg, d.Rule)
+}
+
+// LintRule is a check run over a parsed Document.
+type LintRule struct {
+	ID  string
+	Doc string
+
+	check func(l *linter)
+}
+
+// LintRules are the rules run by Lint.
+var LintRules = []*LintRule{
+	{"undefined-type", "Types referenced by members, bases and attributes must be defined, includes and bases must not form cycles.", lintUndefinedTypes},
+	{"duplicate-name", "Data structures and the members of a list must have unique names.", lintDuplicateNames},
+	{"empty-model", "Data structures must have members or a base type.", lintEmptyModels},
+	{"missing-description", "Data structures, resources and actions should have a description.", lintMissingDescriptions},
+	{"naming-case", "Data structure and property names should follow the case used by most of them.", lintNamingCase},
+	{"unreachable", "Data structures should be used by a resource, directly or through another data structure.", lintUnreachable},
+}
+
+// LintConfig enables and disables lint rules.
+type LintConfig struct {
+	// Rules maps rule IDs to whether they run, rules not listed run.
+	Rules map[string]bool `json:"rules"`
+}
+
+// ReadLintConfig reads a JSON lint config such as
+// {"rules": {"missing-description": false}} from filename.
+func ReadLintConfig(filename string) (*LintConfig, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &LintConfig{}
+	if err := json.Unmarshal(b, config); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+	return config, nil
+}
+
+// validate returns an error for the first rule ID that is not a LintRule.
+func (c *LintConfig) validate() error {
+	ids := make([]string, 0, len(c.Rules))
+	for id := range c.Rules {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if lintRule(id) == nil {
+			return fmt.Errorf("unknown lint rule %q", id)
+		}
+	}
+	return nil
+}
+
+// enabled reports whether the rule id runs, a nil config runs every rule.
+func (c *LintConfig) enabled(id string) bool {
+	if c == nil {
+		return true
+	}
+	on, ok := c.Rules[id]
+	return !ok || on
+}
+
+func lintRule(id string) *LintRule {
+	for _, rule := range LintRules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+// LintFile parses the blueprint in filename and lints it with config.
+// Includes and bases that cannot be resolved are reported by the
+// undefined-type rule, other parse errors are returned.
+func LintFile(filename string, config *LintConfig) ([]*Diagnostic, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := Parse(filename, string(b))
+	if errs, ok := err.(ErrorList); ok {
+		for _, e := range errs {
+			if !e.Reference {
+				return nil, errs
+			}
+		}
+	}
+
+	return Lint(doc, config), nil
+}
+
+// Lint runs the rules enabled by config over doc and returns the
+// diagnostics ordered by position.
+func Lint(doc *Document, config *LintConfig) []*Diagnostic {
+	l := &linter{doc: doc}
+	for _, rule := range LintRules {
+		if config.enabled(rule.ID) {
+			l.rule = rule.ID
+			rule.check(l)
+		}
+	}
+
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		return l.diagnostics[i].Pos.Offset < l.diagnostics[j].Pos.Offset
+	})
+	return l.diagnostics
+}
+
+type linter struct {
+	doc         *Document
+	rule        string
+	diagnostics []*Diagnostic
+}
+
+func (l *linter) report(pos Position, format string, args ...interface{}) {
+	l.diagnostics = append(l.diagnostics, &Diagnostic{pos, l.rule, fmt.Sprintf(format, args...)})
+}
+
+// sections returns the Attributes sections of the resources, actions and
+// payloads in doc.
+func (l *linter) sections() []*DataStructure {
+	var sections []*DataStructure
+	add := func(ds *DataStructure) {
+		if ds != nil {
+			sections = append(sections, ds)
+		}
+	}
+
+	for _, resource := range l.doc.Resources() {
+		add(resource.Attributes)
+		for _, action := range resource.Actions {
+			add(action.Attributes)
+			for _, r := range action.Requests {
+				add(r.Attributes)
+			}
+			for _, r := range action.Responses {
+				add(r.Attributes)
+			}
+		}
+	}
+	return sections
+}
+
+// walk calls fn for each of properties and their nested members.
+func walk(properties []*Property, fn func(*Property)) {
+	for _, property := range properties {
+		fn(property)
+		walk(property.Properties, fn)
+	}
+}
+
+// typeRefs calls fn with each named type referenced by ds and its members.
+func typeRefs(ds *DataStructure, fn func(name string, pos Position)) {
+	if !isBaseType(ds.Type) {
+		fn(ds.Type, ds.Pos)
+	}
+
+	walk(ds.Properties, func(property *Property) {
+		switch {
+		case property.Include != "":
+			fn(property.Include, property.Pos)
+		case !isBaseType(property.Type):
+			fn(property.Type, property.Pos)
+		}
+	})
+}
+
+func lintUndefinedTypes(l *linter) {
+	reported := map[Position]bool{}
+	check := func(name string, pos Position) {
+		if l.doc.Lookup(name) != nil {
+			return
+		}
+
+		msg := fmt.Sprintf("undefined type %q", name)
+		if suggestion := l.closestModel(name); suggestion != "" {
+			msg += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		l.report(pos, "%v", msg)
+		reported[pos] = true
+	}
+
+	for _, ds := range l.doc.DataStructures {
+		typeRefs(ds, check)
+	}
+	for _, ds := range l.sections() {
+		typeRefs(ds, check)
+	}
+
+	// the include and base cycles, undefined ones are reported above.
+	p := &parser{doc: l.doc}
+	for _, err := range append(p.resolveIncludes(), p.resolveBases()...) {
+		if !reported[err.Pos] {
+			l.report(err.Pos, "%v", err.Msg)
+		}
+	}
+}
+
+// closestModel returns the data structure name within two edits of name, or
+// "" when there is none.
+func (l *linter) closestModel(name string) string {
+	best, bestDist := "", 3
+	for _, ds := range l.doc.DataStructures {
+		if d := editDistance(strings.ToLower(name), strings.ToLower(ds.Name)); d < bestDist {
+			best, bestDist = ds.Name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, minInt(cur[j-1]+1, prev[j-1]+cost))
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func lintDuplicateNames(l *linter) {
+	models := map[string]bool{}
+	for _, ds := range l.doc.DataStructures {
+		if models[ds.Name] {
+			l.report(ds.Pos, "duplicate data structure %q", ds.Name)
+		}
+		models[ds.Name] = true
+	}
+
+	var members func(properties []*Property)
+	members = func(properties []*Property) {
+		names := map[string]bool{}
+		for _, property := range properties {
+			if property.Name != "" {
+				if names[property.Name] {
+					l.report(property.Pos, "duplicate member %q", property.Name)
+				}
+				names[property.Name] = true
+			}
+			members(property.Properties)
+		}
+	}
+
+	for _, ds := range l.doc.DataStructures {
+		members(ds.Properties)
+	}
+	for _, ds := range l.sections() {
+		members(ds.Properties)
+	}
+}
+
+func lintEmptyModels(l *linter) {
+	for _, ds := range l.doc.DataStructures {
+		switch {
+		case len(ds.Properties) > 0:
+		case ds.IsEnum:
+			l.report(ds.Pos, "enum %q has no members", ds.Name)
+		case ds.IsArray, isPrimitive(ds.Type), ds.Base() != "":
+		default:
+			l.report(ds.Pos, "data structure %q has no members", ds.Name)
+		}
+	}
+}
+
+func lintMissingDescriptions(l *linter) {
+	for _, ds := range l.doc.DataStructures {
+		if ds.Description == "" {
+			l.report(ds.Pos, "data structure %q has no description", ds.Name)
+		}
+	}
+
+	for _, resource := range l.doc.Resources() {
+		if resource.Description == "" && resource.Name != "" {
+			l.report(resource.Pos, "resource %q has no description", resource.Name)
+		}
+		for _, action := range resource.Actions {
+			if action.Description == "" {
+				name := action.Name
+				if name == "" {
+					name = action.Method + " " + action.URITemplate
+				}
+				l.report(action.Pos, "action %q has no description", name)
+			}
+		}
+	}
+}
+
+// nameCase returns the naming convention of name, "" for a single lower
+// case word which fits most conventions.
+func nameCase(name string) string {
+	hasUpper := strings.IndexFunc(name, unicode.IsUpper) >= 0
+	switch {
+	case strings.ContainsAny(name, " \t"):
+		return "space separated"
+	case strings.Contains(name, "_"):
+		if hasUpper {
+			return "mixed"
+		}
+		return "snake_case"
+	case strings.Contains(name, "-"):
+		if hasUpper {
+			return "mixed"
+		}
+		return "kebab-case"
+	case name != "" && unicode.IsUpper(rune(name[0])):
+		return "PascalCase"
+	case hasUpper:
+		return "camelCase"
+	}
+	return ""
+}
+
+func lintNamingCase(l *linter) {
+	var models []*Property
+	for _, ds := range l.doc.DataStructures {
+		models = append(models, &Property{Name: ds.Name, Pos: ds.Pos})
+	}
+	l.checkCase("data structure", models)
+
+	var properties []*Property
+	var collect func(members []*Property)
+	collect = func(members []*Property) {
+		for _, property := range members {
+			if property.Name != "" {
+				properties = append(properties, property)
+			}
+			// enum members are values rather than names.
+			if !property.IsEnum {
+				collect(property.Properties)
+			}
+		}
+	}
+	for _, ds := range l.doc.DataStructures {
+		if !ds.IsEnum {
+			collect(ds.Properties)
+		}
+	}
+	for _, ds := range l.sections() {
+		collect(ds.Properties)
+	}
+	l.checkCase("property", properties)
+}
+
+// checkCase reports the names that do not follow the convention used by
+// most of them, the first one seen wins a tie.
+func (l *linter) checkCase(kind string, named []*Property) {
+	counts := map[string]int{}
+	var order []string
+	for _, p := range named {
+		c := nameCase(p.Name)
+		if c == "" {
+			continue
+		}
+		if counts[c] == 0 {
+			order = append(order, c)
+		}
+		counts[c]++
+	}
+
+	common := ""
+	for _, c := range order {
+		if counts[c] > counts[common] {
+			common = c
+		}
+	}
+
+	for _, p := range named {
+		if c := nameCase(p.Name); c != "" && c != common {
+			l.report(p.Pos, "%v %q is %v, most are %v", kind, p.Name, c, common)
+		}
+	}
+}
+
+func lintUnreachable(l *linter) {
+	// a document without resources only declares types.
+	if len(l.doc.Resources()) == 0 {
+		return
+	}
+
+	reached := map[string]bool{}
+	var visit func(name string, pos Position)
+	visit = func(name string, pos Position) {
+		ds := l.doc.Lookup(name)
+		if ds == nil || reached[name] {
+			return
+		}
+		reached[name] = true
+		typeRefs(ds, visit)
+	}
+
+	for _, ds := range l.sections() {
+		typeRefs(ds, visit)
+	}
+	for _, resource := range l.doc.Resources() {
+		if resource.Parameters != nil {
+			typeRefs(resource.Parameters, visit)
+		}
+		for _, action := range resource.Actions {
+			if action.Parameters != nil {
+				typeRefs(action.Parameters, visit)
+			}
+		}
+	}
+
+	for _, ds := range l.doc.DataStructures {
+		if !reached[ds.Name] {
+			l.report(ds.Pos, "data structure %q is not used by any resource", ds.Name)
+		}
+	}
+}
+
+// lintMain lints the blueprints named by the arguments and exits with 1 when
+// there are diagnostics.
+func lintMain(args []string) {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	var configFile string
+	var disable string
+	var list bool
+	fs.StringVar(&configFile, "config", "", "JSON config enabling and disabling rules, e.g. {\"rules\": {\"unreachable\": false}}.")
+	fs.StringVar(&disable, "disable", "", "Comma separated rule IDs to disable.")
+	fs.BoolVar(&list, "list", false, "List the rules and exit.")
+	fs.Parse(args)
+
+	if list {
+		for _, rule := range LintRules {
+			fmt.Printf("%-20v %v\n", rule.ID, rule.Doc)
+		}
+		return
+	}
+
+	config := &LintConfig{Rules: map[string]bool{}}
+	if configFile != "" {
+		var err error
+		if config, err = ReadLintConfig(configFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if config.Rules == nil {
+			config.Rules = map[string]bool{}
+		}
+	}
+	for _, id := range strings.Split(disable, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			config.Rules[id] = false
+		}
+	}
+	if err := config.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	failed := false
+	for _, filename := range fs.Args() {
+		diagnostics, err := LintFile(filename, config)
+		if err != nil {
+			printFormatError(err)
+			failed = true
+		}
+		for _, d := range diagnostics {
+			fmt.Println(d)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,198 @@
+package main_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+const lintDoc = `FORMAT: 1A
+
+# Fruit API
+
+## Produce [/produce]
+Fruit.
+
+### List [GET]
++ Response 200 (application/json)
+    + Attributes (array[Produce])
+
+# Data Structures
+
+## Produce
+Something to eat.
+
++ colour (string)
++ dimensions (Dimesion)
++ colour (number)
++ seedCount (number)
++ picked_at (string)
++ best_before (string)
++ grade (enum)
+    + Grade_A
+    + b
+
+## Dimension
++ radius (number)
+
+## Empty
+
+## Colour (enum[string])
+`
+
+func lint(t *testing.T, config *LintConfig) []string {
+	doc, err := Parse("fruit.apib", lintDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var actual []string
+	for _, d := range Lint(doc, config) {
+		actual = append(actual, d.String())
+	}
+	return actual
+}
+
+func Test_Lint_should_report_diagnostics(t *testing.T) {
+	t.Parallel()
+
+	expected := []string{
+		`fruit.apib:8:5: action "List" has no description (missing-description)`,
+		`fruit.apib:18:3: undefined type "Dimesion", did you mean "Dimension"? (undefined-type)`,
+		`fruit.apib:19:3: duplicate member "colour" (duplicate-name)`,
+		`fruit.apib:20:3: property "seedCount" is camelCase, most are snake_case (naming-case)`,
+		`fruit.apib:27:4: data structure "Dimension" has no description (missing-description)`,
+		`fruit.apib:27:4: data structure "Dimension" is not used by any resource (unreachable)`,
+		`fruit.apib:30:4: data structure "Empty" has no members (empty-model)`,
+		`fruit.apib:30:4: data structure "Empty" has no description (missing-description)`,
+		`fruit.apib:30:4: data structure "Empty" is not used by any resource (unreachable)`,
+		`fruit.apib:32:4: enum "Colour" has no members (empty-model)`,
+		`fruit.apib:32:4: data structure "Colour" has no description (missing-description)`,
+		`fruit.apib:32:4: data structure "Colour" is not used by any resource (unreachable)`,
+	}
+
+	actual := lint(t, nil)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Lint() =\n%v\nwant:\n%v", actual, expected)
+	}
+}
+
+func Test_Lint_should_skip_disabled_rules(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "lint.json")
+	err := ioutil.WriteFile(filename, []byte(`{"rules": {"missing-description": false, "unreachable": false, "naming-case": true}}`), 0644)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	config, err := ReadLintConfig(filename)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	expected := []string{
+		`fruit.apib:18:3: undefined type "Dimesion", did you mean "Dimension"? (undefined-type)`,
+		`fruit.apib:19:3: duplicate member "colour" (duplicate-name)`,
+		`fruit.apib:20:3: property "seedCount" is camelCase, most are snake_case (naming-case)`,
+		`fruit.apib:30:4: data structure "Empty" has no members (empty-model)`,
+		`fruit.apib:32:4: enum "Colour" has no members (empty-model)`,
+	}
+
+	actual := lint(t, config)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Lint() =\n%v\nwant:\n%v", actual, expected)
+	}
+}
+
+func Test_ReadLintConfig_should_reject_unknown_rules(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "lint.json")
+	if err := ioutil.WriteFile(filename, []byte(`{"rules": {"no-such-rule": false}}`), 0644); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	_, err := ReadLintConfig(filename)
+	expected := filename + `: unknown lint rule "no-such-rule"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("err = %v, want %v", err, expected)
+	}
+}
+
+func Test_LintFile_should_lint_unresolved_references(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "refs.apib")
+	err := ioutil.WriteFile(filename, []byte(`# API
+# Data Structures
+## Order
+Something bought.
+
++ Include Nope
++ b (Missing)
++ item-count (number)
++ tax_rate (number)
++ unit_price (number)
+
+## A (B)
+Cyclic.
+
++ a (string)
+
+## B (A)
+Cyclic.
+
++ b (string)
+`), 0644)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	dataTable := [][]interface{}{
+		{&LintConfig{Rules: map[string]bool{"unreachable": false}}, []string{
+			filename + `:6:11: undefined type "Nope" (undefined-type)`,
+			filename + `:7:3: undefined type "Missing" (undefined-type)`,
+			filename + `:8:3: property "item-count" is kebab-case, most are snake_case (naming-case)`,
+			filename + `:17:4: base cycle A -> B -> A (undefined-type)`,
+		}},
+		{&LintConfig{Rules: map[string]bool{"unreachable": false, "undefined-type": false}}, []string{
+			filename + `:8:3: property "item-count" is kebab-case, most are snake_case (naming-case)`,
+		}},
+	}
+
+	for i, row := range dataTable {
+		diagnostics, err := LintFile(filename, row[0].(*LintConfig))
+		if err != nil {
+			t.Fatalf("[%v] err = %v, want nil", i, err)
+		}
+
+		var actual []string
+		for _, d := range diagnostics {
+			actual = append(actual, d.String())
+		}
+		if expected := row[1].([]string); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("[%v] LintFile() =\n%v\nwant:\n%v", i, actual, expected)
+		}
+	}
+}
+
+func Test_LintFile_should_return_syntax_errors(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "bad.apib")
+	if err := ioutil.WriteFile(filename, []byte("# API\n# Data Structures\n## A\n+ a (string\n"), 0644); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	if _, err := LintFile(filename, nil); err == nil {
+		t.Errorf("err = nil, want a parse error")
+	}
+}
@@ -5,49 +5,115 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 )
 
-type Property struct {
-	Description string
-	Name        string
-	Type        string
-	IsArray     bool
-}
-
-type DataStructure struct {
-	Name       string
-	Properties []*Property
-}
-
-type MetaData struct {
-	Key   string
-	Value string
-}
-
-type Document struct {
-	MetaData       []*MetaData
-	DataStructures []*DataStructure
-}
-
-func NewDoc() *Document {
-	return &Document{
-		MetaData:       make([]*MetaData, 0, 10),
-		DataStructures: make([]*DataStructure, 0, 10),
+func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "mock":
+			mockMain(os.Args[2:])
+			return
+		case "fmt":
+			fmtMain(os.Args[2:])
+			return
+		case "lint":
+			lintMain(os.Args[2:])
+			return
+		case "go2apib":
+			go2apibMain(os.Args[2:])
+			return
+		}
 	}
-}
 
-func main() {
 	var filename string
 	var pkgname string
+	var format string
+	var output string
+	var numbers string
+	var client bool
+	var server bool
+	var contract bool
 	flag.StringVar(&filename, "input", "", "Input filename.")
-	flag.StringVar(&pkgname, "package", "", "Package name.")
+	flag.StringVar(&pkgname, "package", "", "Package name, required for go.")
+	flag.StringVar(&format, "format", "go", "Output format: go, openapi, jsonschema, typescript or proto.")
+	flag.StringVar(&output, "output", "", "Output directory for jsonschema, one file per data structure.")
+	flag.StringVar(&numbers, "numbers", "string", "Protocol Buffers type of numbers: string or double.")
+	flag.BoolVar(&client, "client", false, "Generate an HTTP client for the actions.")
+	flag.BoolVar(&server, "server", false, "Generate a server interface and http.Handler for the actions.")
+	flag.BoolVar(&contract, "contract", false, "Generate VerifyContract for a _test.go file instead of the models.")
 	flag.Parse()
 
-	if filename == "" || pkgname == "" {
+	if filename == "" || format == "go" && pkgname == "" {
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	doc := parseFile(filename)
+
+	var err error
+	switch format {
+	case "go":
+		w := NewGoWriter(os.Stdout, pkgname)
+		w.Client = client
+		w.Server = server
+		if contract {
+			err = w.WriteContract(doc)
+		} else {
+			err = w.WriteDoc(doc)
+		}
+
+	case "openapi":
+		err = NewOpenAPIWriter(os.Stdout).WriteDoc(doc)
+
+	case "jsonschema":
+		if output == "" {
+			err = NewJSONSchemaWriter(os.Stdout).WriteDoc(doc)
+		} else {
+			err = writeSchemaFiles(doc, output)
+		}
+
+	case "typescript":
+		err = NewTypeScriptWriter(os.Stdout).WriteDoc(doc)
+
+	case "proto":
+		w := NewProtoWriter(os.Stdout, pkgname)
+		w.Numbers = numbers
+		err = w.WriteDoc(doc)
+
+	default:
+		err = fmt.Errorf("unknown format %q", format)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// writeSchemaFiles writes the JSON Schema of each data structure in doc to its
+// own file in dir.
+func writeSchemaFiles(doc *Document, dir string) error {
+	for _, ds := range doc.DataStructures {
+		f, err := os.Create(filepath.Join(dir, schemaFile(ds.Name)))
+		if err != nil {
+			return err
+		}
+
+		err = NewJSONSchemaWriter(f).WriteSchema(doc, ds)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseFile parses the blueprint in filename, it prints every error and exits
+// when the file cannot be read or parsed.
+func parseFile(filename string) *Document {
 	r, err := os.Open(filename)
 	if err != nil {
 		fmt.Println(err)
@@ -60,63 +126,13 @@ func main() {
 		os.Exit(1)
 	}
 
-	l := New(filename, string(b))
-
-	go func() {
-		l.Run()
-	}()
-
-	doc := NewDoc()
-
-	var md *MetaData
-	var model *DataStructure
-	var prop *Property
-
-	for item := range l.Items {
-		switch item.Type {
-		case ItemError:
-			fmt.Printf("%v\n", item.Value)
-			os.Exit(1)
-
-		case ItemMetaKey:
-			md = &MetaData{}
-			md.Key = item.Value
-			continue
-
-		case ItemMetaValue:
-			md.Value = item.Value
-			doc.MetaData = append(doc.MetaData, md)
-			md = nil
-			continue
-
-		case ItemModel:
-			model = &DataStructure{}
-			doc.DataStructures = append(doc.DataStructures, model)
-			model.Name = item.Value
-			continue
-
-		case ItemPropertyName:
-			prop = &Property{}
-			model.Properties = append(model.Properties, prop)
-			prop.Name = item.Value
-			continue
-
-		case ItemPropertyType:
-			prop.Type = item.Value
-			prop.IsArray = false
-			continue
-
-		case ItemPropertyArrayType:
-			prop.Type = item.Value
-			prop.IsArray = true
-			continue
+	doc, err := Parse(filename, string(b))
+	if errs, ok := err.(ErrorList); ok {
+		for _, e := range errs {
+			fmt.Fprintln(os.Stderr, e)
 		}
+		os.Exit(1)
 	}
 
-	w := &GoWriter{
-		os.Stdout,
-		pkgname,
-	}
-
-	w.WriteDoc(doc)
+	return doc
 }
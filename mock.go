@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+// NewMockHandler returns an http.Handler that answers the actions of doc with
+// the status code, headers and body of their first response. Requests are
+// matched by method and URI template, actions without a response answer 204.
+func NewMockHandler(doc *Document) http.Handler {
+	r := &apib.Router{}
+	for _, resource := range doc.Resources() {
+		for _, action := range resource.Actions {
+			var response *Response
+			if len(action.Responses) > 0 {
+				response = action.Responses[0]
+			}
+			r.Handle(action.Method, action.URITemplate, mockResponse(response))
+		}
+	}
+	return r
+}
+
+// mockResponse returns a handler that writes response.
+func mockResponse(response *Response) apib.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+		if response == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		for name, value := range response.Headers {
+			w.Header().Set(name, value)
+		}
+		if response.MediaType != "" && w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", response.MediaType)
+		}
+
+		w.WriteHeader(response.StatusCode)
+		if response.Body != "" {
+			fmt.Fprintln(w, response.Body)
+		}
+	}
+}
+
+// mockMain runs the mock subcommand with args, e.g.
+//
+//	apib2go mock -input api.apib -listen :8080
+func mockMain(args []string) {
+	var filename string
+	var listen string
+	fs := flag.NewFlagSet("mock", flag.ExitOnError)
+	fs.StringVar(&filename, "input", "", "Input filename.")
+	fs.StringVar(&listen, "listen", ":8080", "Address to listen on.")
+	fs.Parse(args)
+
+	if filename == "" {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	doc := parseFile(filename)
+	for _, resource := range doc.Resources() {
+		for _, action := range resource.Actions {
+			log.Printf("%v %v", action.Method, action.URITemplate)
+		}
+	}
+
+	log.Printf("serving mock %v on %v", doc.Title, listen)
+	log.Fatal(http.ListenAndServe(listen, NewMockHandler(doc)))
+}
@@ -0,0 +1,69 @@
+package main_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+func Test_NewMockHandler_should_serve_example_responses(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("notes.apib", payloadDoc+`
+
+## Notes [/notes]
+
+### Create a Note [POST]
++ Response 201
+    + Headers
+
+            Location: /notes/2
+
+## Health [/health]
+
+### Ping [GET]
++ Response 200 (text/plain)
+
+        pong
+
+### Reset [DELETE]`)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	h := NewMockHandler(doc)
+
+	// method, url, status, content type, body
+	dataTable := [][]interface{}{
+		{"PUT", "/notes/1", 200, "application/json", ""},
+		{"POST", "/notes", 201, "", ""},
+		{"GET", "/health", 200, "text/plain", "pong\n"},
+		{"DELETE", "/health", 204, "", ""},
+		{"GET", "/notes/1", 405, "text/plain; charset=utf-8", "Method Not Allowed\n"},
+		{"GET", "/tags", 404, "text/plain; charset=utf-8", "404 page not found\n"},
+	}
+
+	for i, td := range dataTable {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(td[0].(string), td[1].(string), nil))
+
+		if w.Code != td[2].(int) {
+			t.Errorf("[%v] %v %v status = %v, want %v", i, td[0], td[1], w.Code, td[2])
+		}
+
+		if ct := w.Header().Get("Content-Type"); ct != td[3].(string) {
+			t.Errorf("[%v] Content-Type = %q, want %q", i, ct, td[3])
+		}
+
+		if td[4].(string) != "" && w.Body.String() != td[4].(string) {
+			t.Errorf("[%v] body = %q, want %q", i, w.Body.String(), td[4])
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/notes", nil))
+	if loc := w.Header().Get("Location"); loc != "/notes/2" {
+		t.Errorf("Location = %q, want /notes/2", loc)
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+)
+
+// commonInitialisms are written in upper case as golint expects, e.g. UserID.
+var commonInitialisms = map[string]bool{
+	"ACL":   true,
+	"API":   true,
+	"ASCII": true,
+	"CPU":   true,
+	"CSS":   true,
+	"DNS":   true,
+	"EOF":   true,
+	"GUID":  true,
+	"HTML":  true,
+	"HTTP":  true,
+	"HTTPS": true,
+	"ID":    true,
+	"IP":    true,
+	"JSON":  true,
+	"LHS":   true,
+	"QPS":   true,
+	"RAM":   true,
+	"RHS":   true,
+	"RPC":   true,
+	"SLA":   true,
+	"SMTP":  true,
+	"SQL":   true,
+	"SSH":   true,
+	"TCP":   true,
+	"TLS":   true,
+	"TTL":   true,
+	"UDP":   true,
+	"UI":    true,
+	"UID":   true,
+	"UUID":  true,
+	"URI":   true,
+	"URL":   true,
+	"UTF8":  true,
+	"VM":    true,
+	"XML":   true,
+	"XMPP":  true,
+	"XSRF":  true,
+	"XSS":   true,
+}
+
+// GoName converts an APIB name such as user_id, http-status or first name
+// into an exported Go identifier, e.g. UserID, HTTPStatus and FirstName.
+// Names that would start with a digit are prefixed with X.
+func GoName(name string) string {
+	var s string
+	for _, word := range splitWords(name) {
+		upper := strings.ToUpper(word)
+		if commonInitialisms[upper] {
+			s += upper
+			continue
+		}
+		s += upper[:1] + strings.ToLower(word[1:])
+	}
+
+	if s == "" || !unicode.IsLetter(rune(s[0])) {
+		s = "X" + s
+	}
+
+	return s
+}
+
+// GoParam converts an APIB name into an unexported Go identifier, e.g.
+// user_id becomes userID. Go keywords are suffixed with an underscore.
+func GoParam(name string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return "x"
+	}
+
+	s := strings.ToLower(words[0])
+	if len(words) > 1 {
+		s += GoName(strings.Join(words[1:], " "))
+	}
+
+	if !unicode.IsLetter(rune(s[0])) {
+		s = "x" + s
+	}
+
+	if token.Lookup(s).IsKeyword() {
+		s += "_"
+	}
+
+	return s
+}
+
+// SnakeName converts an APIB name into a lower case snake_case identifier as
+// used by Protocol Buffers fields, e.g. HTTPStatus becomes http_status.
+// Names that would start with a digit are prefixed with x_.
+func SnakeName(name string) string {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return "x"
+	}
+
+	s := strings.ToLower(strings.Join(words, "_"))
+	if !unicode.IsLetter(rune(s[0])) {
+		s = "x_" + s
+	}
+
+	return s
+}
+
+// splitWords splits name at characters that are not letters or digits and
+// at camel case boundaries, HTTPStatus is split into HTTP and Status.
+func splitWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := -1
+
+	for i, r := range runes {
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r)) || r > unicode.MaxASCII {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start < 0 {
+			start = i
+			continue
+		}
+
+		prev := runes[i-1]
+		lowerToUpper := unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev))
+		acronymEnd := unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if lowerToUpper || acronymEnd {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+// goNames hands out Go identifiers that are unique within a scope such as
+// the fields of a struct.
+type goNames map[string]bool
+
+// unique returns name or name suffixed with the lowest free number.
+func (names goNames) unique(name string) string {
+	candidate := name
+	for i := 2; names[candidate]; i++ {
+		candidate = fmt.Sprintf("%v%v", name, i)
+	}
+	names[candidate] = true
+	return candidate
+}
@@ -0,0 +1,82 @@
+package main_test
+
+import (
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+func Test_GoName(t *testing.T) {
+	t.Parallel()
+
+	// name, expected
+	dataTable := [][]interface{}{
+		{"colour", "Colour"},
+		{"user_id", "UserID"},
+		{"id", "ID"},
+		{"url", "URL"},
+		{"http-status", "HTTPStatus"},
+		{"HTTPStatus", "HTTPStatus"},
+		{"userId", "UserID"},
+		{"first name", "FirstName"},
+		{"2fa", "X2fa"},
+		{"type", "Type"},
+		{"AdminUser", "AdminUser"},
+		{"api.v2", "APIV2"},
+		{"__", "X"},
+	}
+
+	for i, td := range dataTable {
+		actual := GoName(td[0].(string))
+		expected := td[1].(string)
+		if actual != expected {
+			t.Errorf("[%v] GoName(%q) = %v, want %v", i, td[0], actual, expected)
+		}
+	}
+}
+
+func Test_GoParam(t *testing.T) {
+	t.Parallel()
+
+	// name, expected
+	dataTable := [][]interface{}{
+		{"id", "id"},
+		{"user_id", "userID"},
+		{"type", "type_"},
+		{"range", "range_"},
+		{"2fa", "x2fa"},
+		{"HTTPStatus", "httpStatus"},
+		{"", "x"},
+	}
+
+	for i, td := range dataTable {
+		actual := GoParam(td[0].(string))
+		expected := td[1].(string)
+		if actual != expected {
+			t.Errorf("[%v] GoParam(%q) = %v, want %v", i, td[0], actual, expected)
+		}
+	}
+}
+
+func Test_SnakeName(t *testing.T) {
+	t.Parallel()
+
+	// name, expected
+	dataTable := [][]interface{}{
+		{"id", "id"},
+		{"userId", "user_id"},
+		{"content-type", "content_type"},
+		{"HTTPStatus", "http_status"},
+		{"first name", "first_name"},
+		{"2fa", "x_2fa"},
+		{"", "x"},
+	}
+
+	for i, td := range dataTable {
+		actual := SnakeName(td[0].(string))
+		expected := td[1].(string)
+		if actual != expected {
+			t.Errorf("[%v] SnakeName(%q) = %v, want %v", i, td[0], actual, expected)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nfisher/apib2go/apib"
+)
+
+// OpenAPIWriter writes a Document as an OpenAPI 3.0 document in JSON, which
+// is also valid YAML.
+type OpenAPIWriter struct {
+	io.Writer
+	schemas *schemaBuilder
+}
+
+func NewOpenAPIWriter(w io.Writer) *OpenAPIWriter {
+	return &OpenAPIWriter{Writer: w}
+}
+
+type openAPI struct {
+	OpenAPI    string     `json:"openapi"`
+	Info       info       `json:"info"`
+	Servers    []server   `json:"servers,omitempty"`
+	Paths      orderedMap `json:"paths"`
+	Components components `json:"components,omitempty"`
+}
+
+type info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type server struct {
+	URL string `json:"url"`
+}
+
+type components struct {
+	Schemas orderedMap `json:"schemas,omitempty"`
+}
+
+type operation struct {
+	OperationID string       `json:"operationId"`
+	Summary     string       `json:"summary,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Tags        []string     `json:"tags,omitempty"`
+	Parameters  []*parameter `json:"parameters,omitempty"`
+	RequestBody *requestBody `json:"requestBody,omitempty"`
+	Responses   orderedMap   `json:"responses"`
+}
+
+type parameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required,omitempty"`
+	Schema      *schema     `json:"schema"`
+	Example     interface{} `json:"example,omitempty"`
+}
+
+type requestBody struct {
+	Content orderedMap `json:"content"`
+}
+
+type response struct {
+	Description string     `json:"description"`
+	Headers     orderedMap `json:"headers,omitempty"`
+	Content     orderedMap `json:"content,omitempty"`
+}
+
+type header struct {
+	Schema  *schema `json:"schema"`
+	Example string  `json:"example,omitempty"`
+}
+
+type mediaType struct {
+	Schema  interface{} `json:"schema,omitempty"`
+	Example interface{} `json:"example,omitempty"`
+}
+
+// WriteDoc writes doc as an OpenAPI document. Data structures are component
+// schemas, resources are paths and actions are their operations.
+func (w *OpenAPIWriter) WriteDoc(doc *Document) error {
+	w.schemas = &schemaBuilder{doc: doc, refURI: func(name string) string {
+		return "#/components/schemas/" + name
+	}}
+
+	api := &openAPI{
+		OpenAPI: "3.0.3",
+		Info: info{
+			Title:       doc.Title,
+			Description: doc.Overview,
+			Version:     "1.0",
+		},
+	}
+
+	for _, md := range doc.MetaData {
+		switch strings.ToUpper(md.Key) {
+		case "VERSION":
+			api.Info.Version = md.Value
+		case "HOST":
+			api.Servers = append(api.Servers, server{md.Value})
+		}
+	}
+
+	for _, ds := range doc.DataStructures {
+		s := w.schemas.dataStructure(ds)
+		s.Description = ds.Description
+		api.Components.Schemas.set(ds.Name, s)
+	}
+
+	api.Paths = orderedMap{}
+	ids := goNames{}
+	for _, group := range doc.ResourceGroups {
+		for _, resource := range group.Resources {
+			for _, action := range resource.Actions {
+				path, _, _ := apib.SplitTemplate(action.URITemplate)
+				item, _ := api.Paths.get(path).(orderedMap)
+				op := w.operation(group, resource, action)
+				op.OperationID = ids.unique(actionName(resource, action))
+				item.set(strings.ToLower(action.Method), op)
+				api.Paths.set(path, item)
+			}
+		}
+	}
+
+	b, err := json.MarshalIndent(api, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+// operation converts action into an OpenAPI operation.
+func (w *OpenAPIWriter) operation(group *ResourceGroup, resource *Resource, action *Action) *operation {
+	op := &operation{
+		Summary:     action.Name,
+		Description: action.Description,
+	}
+	if group.Name != "" {
+		op.Tags = []string{group.Name}
+	}
+
+	_, pathVars, queryVars := apib.SplitTemplate(action.URITemplate)
+	for _, name := range pathVars {
+		op.Parameters = append(op.Parameters, w.parameter(resource, action, name, "path"))
+	}
+	for _, name := range queryVars {
+		op.Parameters = append(op.Parameters, w.parameter(resource, action, name, "query"))
+	}
+
+	for _, request := range action.Requests {
+		attrs := request.Attributes
+		if attrs == nil {
+			attrs = action.Attributes
+		}
+		if content := w.content(request.Payload, attrs); len(content) > 0 {
+			op.RequestBody = &requestBody{content}
+			break
+		}
+	}
+	if op.RequestBody == nil && action.Attributes != nil {
+		op.RequestBody = &requestBody{w.content(Payload{}, action.Attributes)}
+	}
+
+	responses := append([]*Response(nil), action.Responses...)
+	sort.SliceStable(responses, func(i, j int) bool {
+		return responses[i].StatusCode < responses[j].StatusCode
+	})
+
+	op.Responses = orderedMap{}
+	for _, r := range responses {
+		code := strconv.Itoa(r.StatusCode)
+		if op.Responses.get(code) != nil {
+			continue
+		}
+		op.Responses.set(code, w.response(r))
+	}
+	if len(op.Responses) == 0 {
+		op.Responses.set("default", &response{Description: "Default response"})
+	}
+
+	return op
+}
+
+// parameter returns the URI template variable name as a parameter, described
+// by the parameters of the action or resource.
+func (w *OpenAPIWriter) parameter(resource *Resource, action *Action, name, in string) *parameter {
+	p := &parameter{
+		Name:     name,
+		In:       in,
+		Required: in == "path",
+		Schema:   &schema{Type: "string"},
+	}
+
+	if property := action.Parameter(resource, name); property != nil {
+		p.Description = property.Description
+		p.Required = p.Required || property.Required
+		p.Schema = w.schemas.property(&Property{
+			Type:       property.Type,
+			IsArray:    property.IsArray,
+			IsEnum:     property.IsEnum,
+			Properties: property.Properties,
+		})
+		if property.Value != "" {
+			p.Example = sampleValue(property)
+		}
+	}
+
+	return p
+}
+
+// response converts r into an OpenAPI response.
+func (w *OpenAPIWriter) response(r *Response) *response {
+	res := &response{
+		Description: http.StatusText(r.StatusCode),
+		Content:     w.content(r.Payload, r.Attributes),
+	}
+	if res.Description == "" {
+		res.Description = "Status " + strconv.Itoa(r.StatusCode)
+	}
+
+	names := make([]string, 0, len(r.Headers))
+	for name := range r.Headers {
+		// Content-Type is described by the content.
+		if !strings.EqualFold(name, "Content-Type") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		res.Headers.set(name, &header{&schema{Type: "string"}, r.Headers[name]})
+	}
+
+	return res
+}
+
+// content returns the media types of a payload with the schema of attrs and
+// the body as example, JSON bodies are embedded as values.
+func (w *OpenAPIWriter) content(payload Payload, attrs *DataStructure) orderedMap {
+	if payload.Body == "" && payload.Schema == "" && attrs == nil {
+		return nil
+	}
+
+	mt := &mediaType{}
+	if attrs != nil {
+		mt.Schema = w.schemas.dataStructure(attrs)
+	} else if json.Valid([]byte(payload.Schema)) {
+		mt.Schema = json.RawMessage(payload.Schema)
+	}
+
+	if json.Valid([]byte(payload.Body)) {
+		mt.Example = json.RawMessage(payload.Body)
+	} else if payload.Body != "" {
+		mt.Example = payload.Body
+	}
+
+	media := payload.MediaType
+	if media == "" {
+		media = "application/json"
+	}
+
+	return orderedMap{{media, mt}}
+}
@@ -0,0 +1,99 @@
+package main_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+// lookup returns the value at path in the decoded JSON v.
+func lookup(v interface{}, path ...interface{}) interface{} {
+	for _, key := range path {
+		switch k := key.(type) {
+		case string:
+			m, _ := v.(map[string]interface{})
+			v = m[k]
+		case int:
+			a, _ := v.([]interface{})
+			if k >= len(a) {
+				return nil
+			}
+			v = a[k]
+		}
+	}
+	return v
+}
+
+func Test_OpenAPIWriter_WriteDoc_should_convert_document(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("notes.apib", "FORMAT: 1A\nHOST: https://api.example.com\n\n"+payloadDoc+`
+
+### Tag (enum[string])
++ red
++ green
+
+### Labelled (Note)
++ label (string, nullable) - Shown in lists.
++ tags: red, green (array[Tag])
++ One Of
+    + url (string)
+    + text (string)`)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewOpenAPIWriter(&buf).WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var api interface{}
+	if err := json.Unmarshal(buf.Bytes(), &api); err != nil {
+		t.Fatalf("err = %v, want valid JSON in:\n%v", err, buf.String())
+	}
+
+	put := []interface{}{"paths", "/notes/{id}", "put"}
+	ok := append(put[:3:3], "responses", "200")
+	req := append(put[:3:3], "requestBody", "content", "application/json")
+	note := []interface{}{"components", "schemas", "Note"}
+	labelled := []interface{}{"components", "schemas", "Labelled"}
+
+	// path, expected
+	dataTable := [][]interface{}{
+		{[]interface{}{"openapi"}, "3.0.3"},
+		{[]interface{}{"info", "title"}, "Notes API"},
+		{[]interface{}{"servers", 0, "url"}, "https://api.example.com"},
+		{append(put, "operationId"), "UpdateANote"},
+		{append(put, "description"), "Replaces the note."},
+		{append(put, "parameters", 0, "name"), "id"},
+		{append(put, "parameters", 0, "in"), "path"},
+		{append(put, "parameters", 0, "required"), true},
+		{append(put, "parameters", 0, "schema", "type"), "number"},
+		{append(put, "parameters", 0, "example"), float64(1)},
+		{append(req, "schema", "$ref"), "#/components/schemas/Note"},
+		{append(req, "example", "id"), float64(1)},
+		{append(ok, "description"), "OK"},
+		{append(ok, "content", "application/json", "schema", "properties", "id", "type"), "number"},
+		{append(ok, "content", "application/json", "schema", "required"), []interface{}{"id"}},
+		{append(put, "responses", "404", "content", "text/plain", "example"), "not found"},
+		{append(note, "properties", "id", "example"), float64(1)},
+		{[]interface{}{"components", "schemas", "Tag", "enum"}, []interface{}{"red", "green"}},
+		{append(labelled, "allOf", 0, "$ref"), "#/components/schemas/Note"},
+		{append(labelled, "allOf", 1, "properties", "label", "nullable"), true},
+		{append(labelled, "allOf", 1, "properties", "label", "description"), "Shown in lists."},
+		{append(labelled, "allOf", 1, "properties", "tags", "items", "$ref"), "#/components/schemas/Tag"},
+		{append(labelled, "allOf", 1, "properties", "tags", "example"), []interface{}{"red", "green"}},
+		{append(labelled, "allOf", 1, "oneOf", 1, "properties", "text", "type"), "string"},
+	}
+
+	for i, td := range dataTable {
+		actual := lookup(api, td[0].([]interface{})...)
+		if !reflect.DeepEqual(actual, td[1]) {
+			t.Errorf("[%v] %v = %#v, want %#v", i, td[0], actual, td[1])
+		}
+	}
+}
@@ -1 +1,612 @@
 package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseError describes an item that could not be assembled into a Document.
+type ParseError struct {
+	Pos Position
+	Msg string
+	// Reference is set for includes and bases of undefined data structures
+	// and their cycles, the Document is otherwise complete.
+	Reference bool
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Pos, e.Msg)
+}
+
+// ErrorList is a list of ParseErrors in the order they were encountered.
+type ErrorList []*ParseError
+
+func (el ErrorList) Error() string {
+	switch len(el) {
+	case 0:
+		return "no errors"
+	case 1:
+		return el[0].Error()
+	}
+	return fmt.Sprintf("%v (and %v more errors)", el[0], len(el)-1)
+}
+
+// Parse lexes input and assembles the items into a Document. Lexing and
+// parsing continue past errors so the returned ErrorList holds every
+// diagnostic in the input, the Document is always returned and is complete
+// when the error is nil.
+func Parse(filename, input string) (*Document, error) {
+	l := New(filename, input)
+
+	go func() {
+		l.Run()
+	}()
+
+	p := &parser{
+		doc: NewDoc(),
+	}
+	p.doc.Pos = Position{Filename: filename, Line: 1, Column: 1}
+
+	var errs ErrorList
+	for item := range l.Items {
+		if err := p.parseItem(item); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, p.resolveIncludes()...)
+	errs = append(errs, p.resolveBases()...)
+
+	if len(errs) > 0 {
+		return p.doc, errs
+	}
+
+	return p.doc, nil
+}
+
+type parser struct {
+	doc   *Document
+	md    *MetaData
+	model *DataStructure
+	prop  *Property
+	// group, resource and action are the innermost open API sections.
+	group    *ResourceGroup
+	resource *Resource
+	action   *Action
+	// payload is the open request or response, payloadCol the column of its
+	// keyword and section the kind of asset that follows.
+	payload    *Payload
+	payloadCol int
+	section    string
+	// header is the last section title seen.
+	header ItemType
+	// lists is the stack of member lists properties are appended to, nest is
+	// the list an indented member list is appended to.
+	lists []members
+	nest  *members
+}
+
+// members is a list of properties and the property that owns it, owner is
+// nil for the members of a data structure.
+type members struct {
+	list  *[]*Property
+	owner *Property
+}
+
+// add appends property to the innermost member list.
+func (p *parser) add(property *Property) {
+	top := p.lists[len(p.lists)-1]
+	*top.list = append(*top.list, property)
+	p.prop = property
+	p.nest = &members{&property.Properties, property}
+}
+
+// addResource appends resource to the open group, resources outside of a
+// group are added to an unnamed group.
+func (p *parser) addResource(resource *Resource) {
+	if p.group == nil {
+		p.group = &ResourceGroup{Pos: resource.Pos}
+		p.doc.ResourceGroups = append(p.doc.ResourceGroups, p.group)
+	}
+	p.group.Resources = append(p.group.Resources, resource)
+	p.resource = resource
+	p.action = nil
+}
+
+// openPayload makes payload the target of the sections that follow, an
+// asset directly below the request or response is its body.
+func (p *parser) openPayload(payload *Payload, pos Position) {
+	p.payload = payload
+	p.payloadCol = pos.Column
+	p.section = "Body"
+	p.model = nil
+	p.lists = nil
+}
+
+// openParameters adds a parameters section to the open action or resource.
+func (p *parser) openParameters(item Item) *ParseError {
+	params := &DataStructure{Pos: item.Pos}
+	switch {
+	case p.action != nil:
+		p.action.Parameters = params
+	case p.resource != nil:
+		p.resource.Parameters = params
+	default:
+		return p.errorf(item.Pos, "parameters outside of a resource")
+	}
+	p.openMembers(params)
+	return nil
+}
+
+// openMembers makes ds the target of the properties that follow.
+func (p *parser) openMembers(ds *DataStructure) {
+	p.model = ds
+	p.prop = nil
+	p.lists = []members{{&ds.Properties, nil}}
+	p.nest = nil
+}
+
+// parseHeaders adds the Name: value lines of a Headers asset to the open
+// payload, Content-Type is the media type when the payload has none.
+func (p *parser) parseHeaders(item Item) *ParseError {
+	if p.payload.Headers == nil {
+		p.payload.Headers = map[string]string{}
+	}
+
+	for i, line := range strings.Split(item.Value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		colon := strings.Index(line, ":")
+		if colon <= 0 {
+			pos := item.Pos
+			pos.Line += i
+			return p.errorf(pos, "invalid header %q", line)
+		}
+
+		name := strings.TrimSpace(line[:colon])
+		value := strings.TrimSpace(line[colon+1:])
+		p.payload.Headers[name] = value
+		if strings.EqualFold(name, "Content-Type") && p.payload.MediaType == "" {
+			p.payload.MediaType = value
+		}
+	}
+
+	return nil
+}
+
+// dedent removes the indentation common to the non-blank lines of block.
+func dedent(block string) string {
+	lines := strings.Split(strings.Replace(block, "\r\n", "\n", -1), "\n")
+
+	indent := -1
+	for _, line := range lines {
+		text := strings.TrimLeft(line, " \t")
+		if text == "" {
+			continue
+		}
+		if n := len(line) - len(text); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+
+	for i, line := range lines {
+		if len(line) >= indent && indent > 0 {
+			lines[i] = line[indent:]
+		} else {
+			lines[i] = strings.TrimLeft(line, " \t")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (p *parser) errorf(pos Position, format string, args ...interface{}) *ParseError {
+	return &ParseError{
+		Pos: pos,
+		Msg: fmt.Sprintf(format, args...),
+	}
+}
+
+// referenceErrorf returns a ParseError for an include or base that cannot be
+// resolved.
+func (p *parser) referenceErrorf(pos Position, format string, args ...interface{}) *ParseError {
+	err := p.errorf(pos, format, args...)
+	err.Reference = true
+	return err
+}
+
+func (p *parser) parseItem(item Item) *ParseError {
+	switch item.Type {
+	case ItemError:
+		return p.errorf(item.Pos, "%v", item.Value)
+
+	case ItemMetaKey:
+		p.md = &MetaData{Key: item.Value, Pos: item.Pos}
+
+	case ItemMetaValue:
+		if p.md == nil {
+			return p.errorf(item.Pos, "meta value %q without a key", item.Value)
+		}
+		p.md.Value = item.Value
+		p.doc.MetaData = append(p.doc.MetaData, p.md)
+		p.md = nil
+
+	case ItemTitleLevel1:
+		if p.doc.Title == "" {
+			p.doc.Title = item.Value
+		}
+		p.header = item.Type
+
+	case ItemTitleLevel2, ItemTitleLevel3, ItemTitleLevel4, ItemTitleLevel5, ItemTitleLevel6, ItemDataStructures:
+		p.header = item.Type
+
+	case ItemGroup:
+		p.group = &ResourceGroup{Name: item.Value, Pos: item.Pos}
+		p.doc.ResourceGroups = append(p.doc.ResourceGroups, p.group)
+		p.resource = nil
+		p.action = nil
+		p.payload = nil
+		p.header = item.Type
+
+	case ItemResource:
+		p.addResource(&Resource{Name: item.Value, Pos: item.Pos})
+		p.payload = nil
+		p.header = item.Type
+
+	case ItemAction:
+		if p.resource == nil {
+			// `## GET /path` declares a resource and its only action.
+			p.addResource(&Resource{Pos: item.Pos})
+		}
+		p.action = &Action{Name: item.Value, URITemplate: p.resource.URITemplate, Pos: item.Pos}
+		p.resource.Actions = append(p.resource.Actions, p.action)
+		p.payload = nil
+		p.header = item.Type
+
+	case ItemHTTPMethod:
+		if p.action == nil {
+			return p.errorf(item.Pos, "method %q without an action", item.Value)
+		}
+		p.action.Method = item.Value
+
+	case ItemURITemplate:
+		switch {
+		case p.header == ItemAction && p.action != nil:
+			p.action.URITemplate = item.Value
+			if p.resource.URITemplate == "" {
+				p.resource.URITemplate = item.Value
+			}
+		case p.header == ItemResource && p.resource != nil:
+			p.resource.URITemplate = item.Value
+		default:
+			return p.errorf(item.Pos, "URI template %q without a resource", item.Value)
+		}
+
+	case ItemRequest:
+		if p.action == nil {
+			return p.errorf(item.Pos, "request outside of an action")
+		}
+		request := &Request{Payload: Payload{Pos: item.Pos}}
+		p.action.Requests = append(p.action.Requests, request)
+		p.openPayload(&request.Payload, item.Pos)
+
+	case ItemRequestName:
+		if p.action == nil || len(p.action.Requests) == 0 {
+			return p.errorf(item.Pos, "request name %q outside of a request", item.Value)
+		}
+		p.action.Requests[len(p.action.Requests)-1].Name = item.Value
+
+	case ItemResponse:
+		if p.action == nil {
+			return p.errorf(item.Pos, "response outside of an action")
+		}
+		response := &Response{Payload: Payload{Pos: item.Pos}}
+		p.action.Responses = append(p.action.Responses, response)
+		p.openPayload(&response.Payload, item.Pos)
+
+	case ItemStatusCode:
+		if p.action == nil || len(p.action.Responses) == 0 {
+			return p.errorf(item.Pos, "status code %q outside of a response", item.Value)
+		}
+		code, err := strconv.Atoi(item.Value)
+		if err != nil || code < 100 || code > 599 {
+			return p.errorf(item.Pos, "invalid status code %q", item.Value)
+		}
+		p.action.Responses[len(p.action.Responses)-1].StatusCode = code
+
+	case ItemMediaType:
+		if p.payload == nil {
+			return p.errorf(item.Pos, "media type %q outside of a request or response", item.Value)
+		}
+		p.payload.MediaType = item.Value
+
+	case ItemPayloadSection:
+		p.model = nil
+		p.lists = nil
+		if item.Value == "Parameters" {
+			return p.openParameters(item)
+		}
+		if item.Value != "Attributes" {
+			if p.payload == nil {
+				return p.errorf(item.Pos, "%v section outside of a request or response", item.Value)
+			}
+			p.section = item.Value
+			break
+		}
+
+		attrs := &DataStructure{Pos: item.Pos}
+		switch {
+		case p.payload != nil && item.Pos.Column > p.payloadCol:
+			p.payload.Attributes = attrs
+		case p.action != nil:
+			p.action.Attributes = attrs
+			p.payload = nil
+		case p.resource != nil:
+			p.resource.Attributes = attrs
+		default:
+			return p.errorf(item.Pos, "attributes outside of a resource")
+		}
+		p.openMembers(attrs)
+
+	case ItemAsset:
+		if p.payload == nil {
+			return p.errorf(item.Pos, "asset outside of a request or response")
+		}
+		switch p.section {
+		case "Headers":
+			return p.parseHeaders(item)
+		case "Schema":
+			p.payload.Schema = dedent(item.Value)
+		default:
+			p.payload.Body = dedent(item.Value)
+		}
+
+	case ItemOverview:
+		switch {
+		case p.header == ItemGroup:
+			p.group.Description = strings.TrimSpace(item.Value)
+		case p.header == ItemResource:
+			p.resource.Description = strings.TrimSpace(item.Value)
+		case p.header == ItemAction:
+			p.action.Description = strings.TrimSpace(item.Value)
+		// only the overview directly below the API name describes the document.
+		case p.header == ItemTitleLevel1 && p.doc.Overview == "":
+			p.doc.Overview = strings.TrimSpace(item.Value)
+		}
+
+	case ItemModel:
+		p.model = &DataStructure{Name: item.Value, Pos: item.Pos}
+		p.doc.DataStructures = append(p.doc.DataStructures, p.model)
+		p.prop = nil
+		p.lists = []members{{&p.model.Properties, nil}}
+		p.nest = nil
+
+	case ItemModelType:
+		if p.model == nil {
+			return p.errorf(item.Pos, "type %q without a data structure", item.Value)
+		}
+		p.model.Type = item.Value
+		switch {
+		case strings.HasPrefix(item.Value, "enum"):
+			p.model.IsEnum = true
+			p.model.Type = strings.Trim(strings.TrimPrefix(item.Value, "enum"), "[]")
+		case strings.HasPrefix(item.Value, "array"):
+			p.model.IsArray = true
+			p.model.Type = strings.Trim(strings.TrimPrefix(item.Value, "array"), "[]")
+		}
+
+	case ItemModelDesc:
+		if p.model == nil {
+			return p.errorf(item.Pos, "description without a data structure")
+		}
+		p.model.Description = strings.TrimSpace(item.Value)
+
+	case ItemTypeSection:
+		// the section members belong to the enclosing member list.
+		if len(p.lists) == 0 {
+			return p.errorf(item.Pos, "%v section outside of a data structure", item.Value)
+		}
+		top := p.lists[len(p.lists)-1]
+		p.nest = &top
+		// Properties in a One Of is an alternative grouping several members.
+		if item.Value == "Properties" && top.owner != nil && top.owner.OneOf {
+			p.add(&Property{Pos: item.Pos})
+		}
+
+	case ItemPropertyName:
+		if p.model == nil {
+			return p.errorf(item.Pos, "property %q outside of a data structure", item.Value)
+		}
+		p.add(&Property{Name: item.Value, Pos: item.Pos})
+
+	case ItemInclude:
+		if len(p.lists) == 0 {
+			return p.errorf(item.Pos, "include %q outside of a data structure", item.Value)
+		}
+		p.add(&Property{Include: item.Value, Pos: item.Pos})
+		p.nest = nil
+
+	case ItemOneOf:
+		if len(p.lists) == 0 {
+			return p.errorf(item.Pos, "One Of outside of a data structure")
+		}
+		p.add(&Property{OneOf: true, Pos: item.Pos})
+
+	case ItemIndent:
+		nest := p.nest
+		p.nest = nil
+		if nest == nil {
+			// keep the stack balanced with the dedent that follows.
+			if n := len(p.lists); n > 0 {
+				p.lists = append(p.lists, p.lists[n-1])
+			}
+			return p.errorf(item.Pos, "unexpected indentation")
+		}
+		p.lists = append(p.lists, *nest)
+
+	case ItemDedent:
+		if len(p.lists) > 1 {
+			p.lists = p.lists[:len(p.lists)-1]
+		}
+		p.nest = nil
+
+	case ItemPropertyType, ItemPropertyArrayType, ItemPropertyEnumType:
+		if p.prop == nil {
+			return p.errorf(item.Pos, "type %q without a property", item.Value)
+		}
+		// the type may be omitted, e.g. (required).
+		if item.Type == ItemPropertyType && p.prop.SetAttr(item.Value) {
+			break
+		}
+		p.prop.Type = item.Value
+		p.prop.IsArray = item.Type == ItemPropertyArrayType
+		p.prop.IsEnum = item.Type == ItemPropertyEnumType
+
+		// array and enum without a member type.
+		switch {
+		case item.Type != ItemPropertyType:
+		case item.Value == "array":
+			p.prop.Type = ""
+			p.prop.IsArray = true
+		case item.Value == "enum":
+			p.prop.Type = ""
+			p.prop.IsEnum = true
+		}
+
+	case ItemPropertyAttr:
+		if p.prop == nil {
+			return p.errorf(item.Pos, "attribute %q without a property", item.Value)
+		}
+		if !p.prop.SetAttr(item.Value) {
+			return p.errorf(item.Pos, "unknown type attribute %q", item.Value)
+		}
+		if p.prop.Required && p.prop.Optional {
+			return p.errorf(item.Pos, "property %q is both required and optional", p.prop.Name)
+		}
+
+	case ItemPropertyValue:
+		if p.prop == nil {
+			return p.errorf(item.Pos, "value %q without a property", item.Value)
+		}
+		p.prop.Value = item.Value
+
+	case ItemPropertyDesc:
+		if p.prop == nil {
+			return p.errorf(item.Pos, "description %q without a property", item.Value)
+		}
+		p.prop.Description = strings.TrimSpace(strings.TrimPrefix(item.Value, "-"))
+	}
+
+	return nil
+}
+
+// resolveIncludes reports includes of undefined data structures and include
+// cycles such as A includes B includes A.
+func (p *parser) resolveIncludes() ErrorList {
+	var errs ErrorList
+
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := map[string]int{}
+
+	var visit func(ds *DataStructure, path []string)
+	var walk func(properties []*Property, path []string)
+
+	visit = func(ds *DataStructure, path []string) {
+		state[ds.Name] = visiting
+		walk(ds.Properties, append(path, ds.Name))
+		state[ds.Name] = done
+	}
+
+	walk = func(properties []*Property, path []string) {
+		for _, property := range properties {
+			walk(property.Properties, path)
+			if property.Include == "" {
+				continue
+			}
+
+			ds := p.doc.Lookup(property.Include)
+			switch {
+			case ds == nil:
+				errs = append(errs, p.referenceErrorf(property.Pos, "include of undefined data structure %q", property.Include))
+			case state[ds.Name] == visiting:
+				// the cycle starts at the first visit of ds.
+				start := 0
+				for i, name := range path {
+					if name == ds.Name {
+						start = i
+						break
+					}
+				}
+				cycle := strings.Join(append(path[start:len(path):len(path)], ds.Name), " -> ")
+				errs = append(errs, p.referenceErrorf(property.Pos, "include cycle %v", cycle))
+			case state[ds.Name] == 0:
+				visit(ds, path)
+			}
+		}
+	}
+
+	for _, ds := range p.doc.DataStructures {
+		if state[ds.Name] == 0 {
+			visit(ds, nil)
+		}
+	}
+
+	return errs
+}
+
+// resolveBases reports base types of undefined data structures and
+// inheritance cycles such as A (B) and B (A), which would embed a type in
+// itself.
+func (p *parser) resolveBases() ErrorList {
+	var errs ErrorList
+
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := map[string]int{}
+
+	for _, ds := range p.doc.DataStructures {
+		var path []string
+		for ds != nil && state[ds.Name] == 0 {
+			state[ds.Name] = visiting
+			path = append(path, ds.Name)
+
+			name := ds.Base()
+			if name == "" {
+				break
+			}
+
+			base := p.doc.Lookup(name)
+			switch {
+			case base == nil:
+				errs = append(errs, p.referenceErrorf(ds.Pos, "base of undefined data structure %q", name))
+			case state[base.Name] == visiting:
+				// the cycle starts at the first visit of base.
+				start := 0
+				for i, n := range path {
+					if n == base.Name {
+						start = i
+						break
+					}
+				}
+				cycle := strings.Join(append(path[start:len(path):len(path)], base.Name), " -> ")
+				errs = append(errs, p.referenceErrorf(ds.Pos, "base cycle %v", cycle))
+			}
+			ds = base
+		}
+
+		for _, name := range path {
+			state[name] = done
+		}
+	}
+
+	return errs
+}
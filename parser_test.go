@@ -1,6 +1,805 @@
 package main_test
 
-import "testing"
+import (
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
 
 func Test_failure(t *testing.T) {
 }
+
+var fruitDoc = `FORMAT: 1A
+
+# Fruit API
+Fruit distribution API.
+
+## Data Structures
+
+### Dimension
++ radius: 123 (number)
++ length (number)
+
+### Produce
+
++ colour (string) - What colour is it?
++ dimensions (Dimension)
++ seeds (array[number])
++ fruit (boolean) - Is it fruit?`
+
+func Test_Parse_should_assemble_document(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("fruits.apib", fruitDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	if doc.Title != "Fruit API" {
+		t.Errorf("doc.Title = %q, want %q", doc.Title, "Fruit API")
+	}
+
+	if doc.Overview != "Fruit distribution API." {
+		t.Errorf("doc.Overview = %q, want %q", doc.Overview, "Fruit distribution API.")
+	}
+
+	if len(doc.MetaData) != 1 {
+		t.Fatalf("len(doc.MetaData) = %v, want 1", len(doc.MetaData))
+	}
+
+	md := doc.MetaData[0]
+	if md.Key != "FORMAT" || md.Value != "1A" {
+		t.Errorf("doc.MetaData[0] = %v: %v, want FORMAT: 1A", md.Key, md.Value)
+	}
+
+	if len(doc.DataStructures) != 2 {
+		t.Fatalf("len(doc.DataStructures) = %v, want 2", len(doc.DataStructures))
+	}
+
+	// model, index, name, type, array, value, description
+	dataTable := [][]interface{}{
+		{0, 0, "radius", "number", false, "123", ""},
+		{0, 1, "length", "number", false, "", ""},
+		{1, 0, "colour", "string", false, "", "What colour is it?"},
+		{1, 1, "dimensions", "Dimension", false, "", ""},
+		{1, 2, "seeds", "number", true, "", ""},
+		{1, 3, "fruit", "boolean", false, "", "Is it fruit?"},
+	}
+
+	for i, td := range dataTable {
+		model := doc.DataStructures[td[0].(int)]
+		prop := model.Properties[td[1].(int)]
+		actual := []interface{}{prop.Name, prop.Type, prop.IsArray, prop.Value, prop.Description}
+
+		for j, expected := range td[2:] {
+			if actual[j] != expected {
+				t.Errorf("[%v] %v property = %v, want %v", i, model.Name, actual, td[2:])
+				break
+			}
+		}
+	}
+}
+
+func Test_Parse_should_set_type_attributes(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Data Structures
+### Produce
++ name (string, required)
++ colour: yellow (string, optional, default)
++ weight (number, required, nullable)
++ origin (fixed, fixed-type)
++ tags: citrus (array[string], sample)`
+
+	doc, err := Parse("fruits.apib", input)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	// index, type, value, required, optional, fixed, fixed-type, nullable, sample, default
+	dataTable := [][]interface{}{
+		{0, "string", "", true, false, false, false, false, false, false},
+		{1, "string", "yellow", false, true, false, false, false, false, true},
+		{2, "number", "", true, false, false, false, true, false, false},
+		{3, "", "", false, false, true, true, false, false, false},
+		{4, "string", "citrus", false, false, false, false, false, true, false},
+	}
+
+	props := doc.DataStructures[0].Properties
+	for i, td := range dataTable {
+		p := props[td[0].(int)]
+		actual := []interface{}{p.Type, p.Value, p.Required, p.Optional, p.Fixed, p.FixedType, p.Nullable, p.Sample, p.Default}
+
+		for j, expected := range td[1:] {
+			if actual[j] != expected {
+				t.Errorf("[%v] %v = %v, want %v", i, p.Name, actual, td[1:])
+				break
+			}
+		}
+	}
+}
+
+func Test_Parse_should_reject_unknown_attributes(t *testing.T) {
+	t.Parallel()
+
+	input := "# API\n## Data Structures\n### Produce\n+ name (string, mandatory)\n"
+	_, err := Parse("fruits.apib", input)
+
+	expected := `fruits.apib:4:17: unknown type attribute "mandatory"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("err = %v, want %v", err, expected)
+	}
+}
+
+func Test_Parse_should_report_every_error(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Data Structures
+### Produce
++ colour* (string)
++ name (string)
++ weight (number
++ fruit (boolean)
+
+### Dimension
++ radius (array[number)
++ length (number)`
+
+	doc, err := Parse("fruits.apib", input)
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("err = %#v, want ErrorList", err)
+	}
+
+	expected := []string{
+		"fruits.apib:4:9: unexpected character '*' for property name",
+		"fruits.apib:6:17: unexpected character '\\n' for property type",
+		"fruits.apib:10:23: missing closing brace in array type",
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("len(errs) = %v, want %v: %v", len(errs), len(expected), errs)
+	}
+
+	for i, e := range expected {
+		if errs[i].Error() != e {
+			t.Errorf("[%v] err = %q, want %q", i, errs[i].Error(), e)
+		}
+	}
+
+	// model, names of the properties that survived recovery.
+	dataTable := [][]interface{}{
+		{"Produce", []string{"name", "weight", "fruit"}},
+		{"Dimension", []string{"radius", "length"}},
+	}
+
+	if len(doc.DataStructures) != len(dataTable) {
+		t.Fatalf("len(doc.DataStructures) = %v, want %v", len(doc.DataStructures), len(dataTable))
+	}
+
+	for i, td := range dataTable {
+		model := doc.DataStructures[i]
+		if model.Name != td[0].(string) {
+			t.Errorf("[%v] model.Name = %v, want %v", i, model.Name, td[0])
+		}
+
+		names := td[1].([]string)
+		if len(model.Properties) != len(names) {
+			t.Errorf("[%v] len(model.Properties) = %v, want %v", i, len(model.Properties), len(names))
+			continue
+		}
+
+		for j, name := range names {
+			if model.Properties[j].Name != name {
+				t.Errorf("[%v][%v] property = %v, want %v", i, j, model.Properties[j].Name, name)
+			}
+		}
+	}
+}
+
+func Test_Parse_should_record_node_positions(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("fruits.apib", fruitDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	// node, expected position
+	dataTable := [][]interface{}{
+		{doc.Pos, "fruits.apib:1:1"},
+		{doc.MetaData[0].Pos, "fruits.apib:1:1"},
+		{doc.DataStructures[0].Pos, "fruits.apib:8:5"},
+		{doc.DataStructures[0].Properties[1].Pos, "fruits.apib:10:3"},
+		{doc.DataStructures[1].Pos, "fruits.apib:12:5"},
+		{doc.DataStructures[1].Properties[2].Pos, "fruits.apib:16:3"},
+	}
+
+	for i, td := range dataTable {
+		actual := td[0].(Position).String()
+		expected := td[1].(string)
+		if actual != expected {
+			t.Errorf("[%v] pos = %v, want %v", i, actual, expected)
+		}
+	}
+}
+
+func Test_Parse_should_nest_inline_members(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Data Structures
+### Produce
++ colour (string)
++ dimensions (object)
+    + radius (number)
+    + core
+        + size (number)
++ fruit (boolean)`
+
+	doc, err := Parse("fruits.apib", input)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	props := doc.DataStructures[0].Properties
+	if len(props) != 3 {
+		t.Fatalf("len(props) = %v, want 3", len(props))
+	}
+
+	dims := props[1]
+	if !dims.IsInline() || len(dims.Properties) != 2 {
+		t.Fatalf("dimensions = %+v, want 2 inline members", dims)
+	}
+
+	core := dims.Properties[1]
+	if !core.IsInline() || core.Properties[0].Name != "size" {
+		t.Errorf("core = %+v, want inline member size", core)
+	}
+
+	if props[2].Name != "fruit" {
+		t.Errorf("props[2].Name = %v, want fruit", props[2].Name)
+	}
+}
+
+func Test_Parse_should_collect_enum_members(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Data Structures
+### Status (enum[string])
++ Members
+    + active
+    + inactive
+
+### Produce
++ ripeness (enum[string])
+    + Members
+        + green
+        + ripe
++ grade (enum)
+    + a
++ sizes (array)`
+
+	doc, err := Parse("fruits.apib", input)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	status := doc.DataStructures[0]
+	if !status.IsEnum || status.Type != "string" || len(status.Properties) != 2 {
+		t.Errorf("status = %+v, want enum of 2 strings", status)
+	}
+
+	// index, type, enum, array, members
+	dataTable := [][]interface{}{
+		{0, "string", true, false, 2},
+		{1, "", true, false, 1},
+		{2, "", false, true, 0},
+	}
+
+	props := doc.DataStructures[1].Properties
+	for i, td := range dataTable {
+		p := props[td[0].(int)]
+		actual := []interface{}{p.Type, p.IsEnum, p.IsArray, len(p.Properties)}
+
+		for j, expected := range td[1:] {
+			if actual[j] != expected {
+				t.Errorf("[%v] %v = %v, want %v", i, p.Name, actual, td[1:])
+				break
+			}
+		}
+	}
+}
+
+func Test_Parse_should_record_base_types(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Data Structures
+### User (object)
++ name (string)
+
+### AdminUser (User)
++ role (string)
+
+### Email (string)
+
+### Users (array[User])`
+
+	doc, err := Parse("fruits.apib", input)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	// index, type, array, base
+	dataTable := [][]interface{}{
+		{0, "object", false, ""},
+		{1, "User", false, "User"},
+		{2, "string", false, ""},
+		{3, "User", true, ""},
+	}
+
+	for i, td := range dataTable {
+		ds := doc.DataStructures[td[0].(int)]
+		actual := []interface{}{ds.Type, ds.IsArray, ds.Base()}
+
+		for j, expected := range td[1:] {
+			if actual[j] != expected {
+				t.Errorf("[%v] %v = %v, want %v", i, ds.Name, actual, td[1:])
+				break
+			}
+		}
+	}
+}
+
+func Test_Parse_should_read_property_types_with_digits_and_underscores(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Data Structures
+### Dimension2
++ radius (number)
+
+### Foo_Bar
++ size (Dimension2, required)
++ sizes (array[Dimension2])`
+
+	doc, err := Parse("fruits.apib", input)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	// index, type, array, required
+	dataTable := [][]interface{}{
+		{0, "Dimension2", false, true},
+		{1, "Dimension2", true, false},
+	}
+
+	for i, td := range dataTable {
+		p := doc.DataStructures[1].Properties[td[0].(int)]
+		actual := []interface{}{p.Type, p.IsArray, p.Required}
+
+		for j, expected := range td[1:] {
+			if actual[j] != expected {
+				t.Errorf("[%v] %v = %v, want %v", i, p.Name, actual, td[1:])
+				break
+			}
+		}
+	}
+}
+
+func Test_Parse_should_resolve_includes(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Data Structures
+### Timestamps
++ created (string)
++ Include Audit
+
+### Audit
++ editor (string)
+
+### Produce
++ Include Timestamps
++ name (string)`
+
+	doc, err := Parse("fruits.apib", input)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	props := doc.Expand(doc.Lookup("Produce").Properties)
+	expected := []string{"created", "editor", "name"}
+	if len(props) != len(expected) {
+		t.Fatalf("len(props) = %v, want %v", len(props), len(expected))
+	}
+
+	for i, name := range expected {
+		if props[i].Name != name {
+			t.Errorf("[%v] props[i].Name = %v, want %v", i, props[i].Name, name)
+		}
+	}
+}
+
+func Test_Parse_should_report_include_errors(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Data Structures
+### A
++ Include B
+
+### B
++ Include C
+
+### C
++ Include A
++ Include Missing`
+
+	doc, err := Parse("fruits.apib", input)
+
+	expected := ErrorList{
+		{Pos: Position{"fruits.apib", 0, 10, 11}, Msg: "include cycle A -> B -> C -> A", Reference: true},
+		{Pos: Position{"fruits.apib", 0, 11, 11}, Msg: `include of undefined data structure "Missing"`, Reference: true},
+	}
+
+	errs, ok := err.(ErrorList)
+	if !ok || len(errs) != len(expected) {
+		t.Fatalf("err = %v, want %v", err, expected)
+	}
+
+	for i, e := range expected {
+		e.Pos.Offset = errs[i].Pos.Offset
+		if *errs[i] != *e {
+			t.Errorf("[%v] err = %v, want %v", i, errs[i], e)
+		}
+	}
+
+	// expansion stops at the cycle.
+	if props := doc.Expand(doc.Lookup("A").Properties); len(props) != 0 {
+		t.Errorf("len(props) = %v, want 0", len(props))
+	}
+}
+
+func Test_Parse_should_report_base_errors(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Data Structures
+### A (B)
+
+### B (C)
+
+### C (A)
+
+### D (A)
+
+### E (Missing)
+
+### F (F)`
+
+	_, err := Parse("fruits.apib", input)
+
+	expected := ErrorList{
+		{Pos: Position{"fruits.apib", 0, 7, 5}, Msg: "base cycle A -> B -> C -> A", Reference: true},
+		{Pos: Position{"fruits.apib", 0, 11, 5}, Msg: `base of undefined data structure "Missing"`, Reference: true},
+		{Pos: Position{"fruits.apib", 0, 13, 5}, Msg: "base cycle F -> F", Reference: true},
+	}
+
+	errs, ok := err.(ErrorList)
+	if !ok || len(errs) != len(expected) {
+		t.Fatalf("err = %v, want %v", err, expected)
+	}
+
+	for i, e := range expected {
+		e.Pos.Offset = errs[i].Pos.Offset
+		if *errs[i] != *e {
+			t.Errorf("[%v] err = %v, want %v", i, errs[i], e)
+		}
+	}
+}
+
+func Test_Parse_should_collect_one_of_alternatives(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Data Structures
+### Payment
++ amount (number)
++ One Of
+    + card (Card)
+    + Properties
+        + iban (string)
+        + bic (string)`
+
+	doc, err := Parse("fruits.apib", input)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	props := doc.DataStructures[0].Properties
+	if len(props) != 2 || !props[1].OneOf {
+		t.Fatalf("props = %v, want amount and One Of", props)
+	}
+
+	alts := props[1].Properties
+	if len(alts) != 2 {
+		t.Fatalf("len(alts) = %v, want 2", len(alts))
+	}
+
+	if alts[0].Name != "card" || alts[0].Type != "Card" {
+		t.Errorf("alts[0] = %+v, want card (Card)", alts[0])
+	}
+
+	if alts[1].Name != "" || len(alts[1].Properties) != 2 {
+		t.Errorf("alts[1] = %+v, want group of iban and bic", alts[1])
+	}
+}
+
+func Test_Parse_should_collect_descriptions(t *testing.T) {
+	t.Parallel()
+
+	input := `# Fruit API
+Fruit distribution API.
+
+## Data Structures
+
+### Produce
+Anything grown
+for eating.
+
++ colour (string) - What colour is it?`
+
+	doc, err := Parse("fruits.apib", input)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	ds := doc.DataStructures[0]
+	expected := "Anything grown\nfor eating."
+	if ds.Description != expected {
+		t.Errorf("ds.Description = %q, want %q", ds.Description, expected)
+	}
+
+	if len(ds.Properties) != 1 || ds.Properties[0].Description != "What colour is it?" {
+		t.Errorf("ds.Properties = %v, want colour with description", ds.Properties)
+	}
+}
+
+var notesDoc = `FORMAT: 1A
+
+# Notes API
+
+## GET /health
+
+# Group Notes
+Notes of a user.
+
+## Note [/notes/{id}]
+A single note.
+
+### Get a Note [GET]
+
+### Archive a Note [POST /notes/{id}/archive]
+
+## Data Structures
+
+### Note
++ id: 1 (number)`
+
+func Test_Parse_should_build_resource_tree(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("notes.apib", notesDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	if len(doc.ResourceGroups) != 2 {
+		t.Fatalf("len(doc.ResourceGroups) = %v, want 2", len(doc.ResourceGroups))
+	}
+
+	if doc.ResourceGroups[0].Name != "" || doc.ResourceGroups[1].Name != "Notes" {
+		t.Errorf("groups = %q, %q, want unnamed and Notes", doc.ResourceGroups[0].Name, doc.ResourceGroups[1].Name)
+	}
+
+	if doc.ResourceGroups[1].Description != "Notes of a user." {
+		t.Errorf("group description = %q, want %q", doc.ResourceGroups[1].Description, "Notes of a user.")
+	}
+
+	resources := doc.Resources()
+	if len(resources) != 2 {
+		t.Fatalf("len(resources) = %v, want 2", len(resources))
+	}
+
+	// resource, action, name, method, uri
+	dataTable := [][]interface{}{
+		{0, 0, "", "GET", "/health"},
+		{1, 0, "Get a Note", "GET", "/notes/{id}"},
+		{1, 1, "Archive a Note", "POST", "/notes/{id}/archive"},
+	}
+
+	for i, td := range dataTable {
+		r := resources[td[0].(int)]
+		if td[1].(int) >= len(r.Actions) {
+			t.Errorf("[%v] len(actions) = %v", i, len(r.Actions))
+			continue
+		}
+
+		a := r.Actions[td[1].(int)]
+		if a.Name != td[2] || a.Method != td[3] || a.URITemplate != td[4] {
+			t.Errorf("[%v] action = %v %v %v, want %v %v %v", i, a.Name, a.Method, a.URITemplate, td[2], td[3], td[4])
+		}
+	}
+
+	note := resources[1]
+	if note.Name != "Note" || note.URITemplate != "/notes/{id}" || note.Description != "A single note." {
+		t.Errorf("resource = %+v, want Note [/notes/{id}] with description", note)
+	}
+
+	if resources[0].URITemplate != "/health" {
+		t.Errorf("resources[0].URITemplate = %q, want /health", resources[0].URITemplate)
+	}
+
+	if len(doc.DataStructures) != 1 || doc.DataStructures[0].Name != "Note" {
+		t.Errorf("doc.DataStructures = %v, want Note", doc.DataStructures)
+	}
+}
+
+var payloadDoc = `# Notes API
+
+## Note [/notes/{id}]
+
++ Parameters
+    + id: 1 (number) - Id of the note.
+
+### Update a Note [PUT]
+Replaces the note.
+
++ Attributes (Note)
+
++ Request (application/json)
+
+    + Headers
+
+            Authorization: Bearer abc
+            X-Request-ID: 42
+
+    + Body
+
+            {
+                "id": 1
+            }
+
+    + Schema
+
+            {"type": "object"}
+
++ Response 200
+    + Headers
+
+            Content-Type: application/json
+
+    + Attributes
+        + id: 1 (number, required)
+        + title (string)
+
++ Response 404 (text/plain)
+
+        not found
+
+## Data Structures
+
+### Note
++ id: 1 (number)`
+
+func Test_Parse_should_collect_requests_and_responses(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("notes.apib", payloadDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	resources := doc.Resources()
+	if len(resources) != 1 || len(resources[0].Actions) != 1 {
+		t.Fatalf("resources = %v, want one resource with one action", resources)
+	}
+
+	action := resources[0].Actions[0]
+	if action.Description != "Replaces the note." {
+		t.Errorf("action.Description = %q, want %q", action.Description, "Replaces the note.")
+	}
+
+	if action.Attributes == nil || action.Attributes.Type != "Note" {
+		t.Errorf("action.Attributes = %+v, want Note", action.Attributes)
+	}
+
+	if len(action.Requests) != 1 || len(action.Responses) != 2 {
+		t.Fatalf("requests, responses = %v, %v, want 1, 2", len(action.Requests), len(action.Responses))
+	}
+
+	req := action.Requests[0]
+	if req.MediaType != "application/json" {
+		t.Errorf("req.MediaType = %q, want application/json", req.MediaType)
+	}
+
+	if req.Headers["Authorization"] != "Bearer abc" || req.Headers["X-Request-ID"] != "42" {
+		t.Errorf("req.Headers = %v, want Authorization and X-Request-ID", req.Headers)
+	}
+
+	if req.Body != "{\n    \"id\": 1\n}" {
+		t.Errorf("req.Body = %q, want the dedented JSON", req.Body)
+	}
+
+	if req.Schema != `{"type": "object"}` {
+		t.Errorf("req.Schema = %q, want the schema", req.Schema)
+	}
+
+	ok := action.Responses[0]
+	if ok.StatusCode != 200 || ok.MediaType != "application/json" {
+		t.Errorf("ok = %v %q, want 200 application/json", ok.StatusCode, ok.MediaType)
+	}
+
+	if ok.Attributes == nil || len(ok.Attributes.Properties) != 2 || !ok.Attributes.Properties[0].Required {
+		t.Errorf("ok.Attributes = %+v, want id and title", ok.Attributes)
+	}
+
+	notFound := action.Responses[1]
+	if notFound.StatusCode != 404 || notFound.MediaType != "text/plain" || notFound.Body != "not found" {
+		t.Errorf("notFound = %v %q %q, want 404 text/plain not found", notFound.StatusCode, notFound.MediaType, notFound.Body)
+	}
+
+	if len(doc.DataStructures) != 1 || len(doc.DataStructures[0].Properties) != 1 {
+		t.Errorf("doc.DataStructures = %v, want Note with id", doc.DataStructures)
+	}
+
+	id := action.Parameter(resources[0], "id")
+	if id == nil || id.Value != "1" || id.Type != "number" || id.Description != "Id of the note." {
+		t.Errorf("id = %+v, want parameter with example 1", id)
+	}
+
+	transactions := action.Transactions()
+	if len(transactions) != 2 || transactions[0].Request != req || transactions[1].Request != req {
+		t.Errorf("transactions = %v, want both responses paired with the request", transactions)
+	}
+}
+
+func Test_Parse_should_report_payload_errors(t *testing.T) {
+	t.Parallel()
+
+	input := `# API
+## Notes [/notes]
+### List [GET]
++ Response abc
++ Response 200
+    + Headers
+
+            no colon
+`
+
+	_, err := Parse("notes.apib", input)
+	errs, ok := err.(ErrorList)
+	if !ok {
+		t.Fatalf("err = %v, want ErrorList", err)
+	}
+
+	expected := []string{
+		`notes.apib:4:12: invalid status code "abc"`,
+		`notes.apib:8:1: invalid header "no colon"`,
+	}
+
+	if len(errs) != len(expected) {
+		t.Fatalf("errs = %v, want %v", errs, expected)
+	}
+
+	for i, e := range errs {
+		if e.Error() != expected[i] {
+			t.Errorf("errs[%v] = %v, want %v", i, e, expected[i])
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const structProto = "google/protobuf/struct.proto"
+
+// inheritedFields is the offset of the field numbers per level of
+// inheritance, the fields of a base are numbered from 1001 and those of its
+// base from 2001.
+const inheritedFields = 1000
+
+// includedFields is the number of fields reserved for each included type.
+const includedFields = 100
+
+// ProtoWriter writes the data structures of a Document as proto3 messages
+// and enums named like the types of GoWriter. Field numbers follow the
+// declaration order, so properties must be appended to keep the numbers
+// stable. Inherited and included fields are numbered independently of the
+// own fields of a message, adding a property to a base or an included type
+// does not renumber the messages using it.
+type ProtoWriter struct {
+	io.Writer
+	// Numbers is the scalar type of MSON numbers, string to keep their
+	// precision as the Go types do or double.
+	Numbers string
+
+	pkgname string
+	// doc is the document being written, used to expand includes.
+	doc *Document
+	// imports used by the generated messages.
+	imports map[string]bool
+	// owners maps inherited properties to the message declaring them, which
+	// also names their inline types.
+	owners map[*Property]string
+	buf    bytes.Buffer
+}
+
+func NewProtoWriter(w io.Writer, pkgname string) *ProtoWriter {
+	return &ProtoWriter{Writer: w, Numbers: "string", pkgname: pkgname}
+}
+
+// WriteDoc writes the data structures of doc as a proto3 file. Objects and
+// enums of named types are messages, enums are proto enums. Arrays and
+// primitive data structures have no message, properties of those types use
+// the repeated or scalar type instead.
+func (w *ProtoWriter) WriteDoc(doc *Document) error {
+	if w.Numbers != "string" && w.Numbers != "double" {
+		return fmt.Errorf("unknown number type %q, want string or double", w.Numbers)
+	}
+
+	w.doc = doc
+	w.imports = map[string]bool{}
+	w.owners = map[*Property]string{}
+	w.buf.Reset()
+
+	for _, model := range doc.DataStructures {
+		name := GoName(model.Name)
+
+		switch {
+		case model.IsTypeEnum():
+			w.writeComment("", model.Description)
+			w.writeTypeEnum(name, model.Properties)
+
+		case model.IsEnum:
+			w.writeComment("", model.Description)
+			w.writeEnum(name, model.Properties)
+
+		case model.IsArray || isPrimitive(model.Type):
+
+		default:
+			levels := map[*Property]int{}
+			w.writeComment("", model.Description)
+			w.writeMessage(name, w.fields(model, levels, map[string]bool{}), levels)
+		}
+	}
+
+	var out bytes.Buffer
+	if doc.Title != "" {
+		fmt.Fprintf(&out, "// Generated from the %v blueprint.\n\n", doc.Title)
+	}
+	fmt.Fprintf(&out, "syntax = \"proto3\";\n\n")
+	if w.pkgname != "" {
+		fmt.Fprintf(&out, "package %v;\n\n", w.pkgname)
+	}
+
+	imports := make([]string, 0, len(w.imports))
+	for path := range w.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		fmt.Fprintf(&out, "import %q;\n", path)
+	}
+	if len(imports) > 0 {
+		out.WriteByte('\n')
+	}
+
+	out.Write(w.buf.Bytes())
+
+	_, err := w.Write(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+	return err
+}
+
+// fields returns the properties of ds preceded by those of its base types,
+// proto3 has no inheritance. levels counts the levels of inheritance of each
+// inherited property.
+func (w *ProtoWriter) fields(ds *DataStructure, levels map[*Property]int, seen map[string]bool) []*Property {
+	var properties []*Property
+	if base := w.doc.Lookup(ds.Base()); base != nil && !seen[base.Name] {
+		seen[base.Name] = true
+		for _, property := range w.fields(base, levels, seen) {
+			for _, p := range append(w.doc.Expand([]*Property{property}), property) {
+				if _, ok := w.owners[p]; !ok {
+					w.owners[p] = GoName(base.Name)
+				}
+			}
+			levels[property]++
+			properties = append(properties, property)
+		}
+	}
+	return append(properties, ds.Properties...)
+}
+
+// writeComment writes text as a line comment at indent.
+func (w *ProtoWriter) writeComment(indent, text string) {
+	if text == "" {
+		return
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			fmt.Fprintf(&w.buf, "%v//\n", indent)
+			continue
+		}
+		fmt.Fprintf(&w.buf, "%v// %v\n", indent, line)
+	}
+}
+
+// writeMessage writes the message name followed by the types synthesised for
+// its inline properties and grouped One Of alternatives. One Of sections are
+// oneofs, an alternative that is not a single scalar or message becomes a
+// message of its own. levels are the levels of inheritance of inherited
+// properties, which are numbered from their own offset. The members of the
+// nth include of a level are numbered from includedFields*n below the next
+// level.
+func (w *ProtoWriter) writeMessage(name string, properties []*Property, levels map[*Property]int) {
+	var inline []*Property
+	var alternatives []*Property
+	names := goNames{}
+
+	write := func(property *Property, next func() int) {
+		if !property.OneOf {
+			w.writeComment("  ", property.Description)
+			w.writeField("  ", name, property, names, next(), false)
+			inline = append(inline, property)
+			return
+		}
+
+		fmt.Fprintf(&w.buf, "  oneof %v {\n", names.unique(SnakeName(oneOfField(len(alternatives)+1))))
+		for _, alt := range property.Properties {
+			n := next()
+			if alt.Name != "" && !alt.IsArray {
+				w.writeComment("    ", alt.Description)
+				w.writeField("    ", name, alt, names, n, true)
+				inline = append(inline, alt)
+				continue
+			}
+
+			altName := alternativeName(w.doc, name, alt)
+			field := names.unique(SnakeName(strings.TrimPrefix(altName, name)))
+			fmt.Fprintf(&w.buf, "    %v %v = %v;\n", altName, field, n)
+		}
+		fmt.Fprintf(&w.buf, "  }\n")
+		alternatives = append(alternatives, property)
+	}
+
+	counts := map[int]int{}
+	includes := map[int]int{}
+
+	fmt.Fprintf(&w.buf, "message %v {\n", name)
+	for _, property := range properties {
+		level := levels[property]
+		offset := level * inheritedFields
+		if property.Include == "" {
+			write(property, func() int {
+				counts[level]++
+				return offset + counts[level]
+			})
+			continue
+		}
+
+		includes[level]++
+		offset += inheritedFields - includedFields*includes[level]
+		n := 0
+		for _, member := range w.doc.Expand([]*Property{property}) {
+			write(member, func() int {
+				n++
+				return offset + n
+			})
+		}
+	}
+	fmt.Fprintf(&w.buf, "}\n\n")
+
+	for _, oneOf := range alternatives {
+		for _, alt := range oneOf.Properties {
+			if alt.Name == "" || alt.IsArray {
+				w.writeMessage(alternativeName(w.doc, name, alt), alternativeMembers(alt), nil)
+			}
+		}
+	}
+
+	for _, property := range inline {
+		if _, ok := w.owners[property]; ok {
+			continue
+		}
+
+		switch {
+		case property.IsInline():
+			w.writeMessage(inlineName(name, property), property.Properties, nil)
+		case property.IsTypeEnum():
+			w.writeTypeEnum(inlineName(name, property), property.Properties)
+		case property.IsInlineEnum():
+			w.writeEnum(inlineName(name, property), property.Properties)
+		}
+	}
+}
+
+// writeField writes property as field number n of the message parent. The
+// field is optional when it is a scalar or enum that may be absent, which is
+// not expressible inside a oneof.
+func (w *ProtoWriter) writeField(indent, parent string, property *Property, names goNames, n int, inOneOf bool) {
+	t, repeated, scalar := w.protoType(parent, property)
+
+	label := ""
+	switch {
+	case repeated:
+		label = "repeated "
+	case scalar && !inOneOf && (!property.Required || property.Nullable):
+		label = "optional "
+	}
+
+	field := names.unique(SnakeName(property.Name))
+	option := ""
+	if jsonName(field) != property.Name {
+		option = fmt.Sprintf(" [json_name = %q]", property.Name)
+	}
+
+	fmt.Fprintf(&w.buf, "%v%v%v %v = %v%v;\n", indent, label, t, field, n, option)
+}
+
+// writeTypeEnum writes an enum of named types as a message holding one of
+// them.
+func (w *ProtoWriter) writeTypeEnum(name string, members []*Property) {
+	names := goNames{}
+	fmt.Fprintf(&w.buf, "message %v {\n", name)
+	fmt.Fprintf(&w.buf, "  oneof value {\n")
+	for i, member := range members {
+		t, _, _ := w.protoType(name, &Property{Type: member.Type})
+		fmt.Fprintf(&w.buf, "    %v %v = %v;\n", t, names.unique(SnakeName(member.Type)), i+1)
+	}
+	fmt.Fprintf(&w.buf, "  }\n")
+	fmt.Fprintf(&w.buf, "}\n\n")
+}
+
+// writeEnum writes a proto enum name with a value per member prefixed with
+// the enum name, NAME_UNSPECIFIED is the zero value.
+func (w *ProtoWriter) writeEnum(name string, members []*Property) {
+	members = w.doc.Expand(members)
+
+	prefix := strings.ToUpper(SnakeName(name)) + "_"
+	names := goNames{prefix + "UNSPECIFIED": true}
+
+	fmt.Fprintf(&w.buf, "enum %v {\n", name)
+	fmt.Fprintf(&w.buf, "  %vUNSPECIFIED = 0;\n", prefix)
+	for i, member := range members {
+		value := strings.ToUpper(strings.Join(splitWords(member.Name), "_"))
+		if value == "" {
+			value = "VALUE"
+		}
+		w.writeComment("  ", member.Description)
+		fmt.Fprintf(&w.buf, "  %v = %v;\n", names.unique(prefix+value), i+1)
+	}
+	fmt.Fprintf(&w.buf, "}\n\n")
+}
+
+// protoType returns the type of property in the message parent, whether the
+// field is repeated and whether the type is a scalar or enum without
+// presence. Arrays and primitives declared as data structures are resolved
+// to their members.
+func (w *ProtoWriter) protoType(parent string, property *Property) (t string, repeated, scalar bool) {
+	repeated = property.IsArray
+	if owner, ok := w.owners[property]; ok {
+		parent = owner
+	}
+
+	switch t = property.Type; {
+	case property.IsInline() || property.IsTypeEnum():
+		return inlineName(parent, property), repeated, false
+	case property.IsInlineEnum():
+		return inlineName(parent, property), repeated, true
+	case t == "" || t == "string" || t == "enum":
+		return "string", repeated, true
+	case t == "number":
+		return w.Numbers, repeated, true
+	case t == "boolean":
+		return "bool", repeated, true
+	case t == "object":
+		w.imports[structProto] = true
+		return "google.protobuf.Struct", repeated, false
+	case t == "array":
+		w.imports[structProto] = true
+		return "google.protobuf.ListValue", repeated, false
+	}
+
+	ds := w.doc.Lookup(t)
+	switch {
+	case ds == nil || ds.IsTypeEnum():
+	case ds.IsEnum:
+		scalar = true
+	case ds.IsArray && !repeated:
+		t, _, scalar = w.protoType(parent, &Property{Type: ds.Type})
+		return t, true, scalar
+	case ds.IsArray:
+		w.imports[structProto] = true
+		return "google.protobuf.ListValue", true, false
+	case isPrimitive(ds.Type):
+		return w.protoType(parent, &Property{Type: ds.Type, IsArray: repeated})
+	}
+	return GoName(t), repeated, scalar
+}
+
+// jsonName returns the JSON name protoc derives from a field name, each
+// underscore is dropped and the letter following it upper cased.
+func jsonName(field string) string {
+	var s []rune
+	upper := false
+	for _, r := range field {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		s = append(s, r)
+	}
+	return string(s)
+}
@@ -0,0 +1,235 @@
+package main_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+const protoDoc = `# Fruit API
+## Data Structures
+### Dimension
++ radius (number)
++ unit (enum[string])
+    + cm
+    + inch
+
+### Produce (Dimension)
+Something to eat.
+
++ colour: yellow (string, required) - What colour is it?
++ seeds (array[object])
+    + weight (number)
++ content-type (string, nullable)
++ grade (Grade)
++ tags (Tags)
++ data (object)
++ One Of
+    + barcode (string)
+    + Properties
+        + lot (number)
+
+### Grade (enum[string])
++ premium
++ standard
+
+### Tags (array[string])`
+
+func Test_ProtoWriter_WriteDoc_should_write_proto3(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("fruit.apib", protoDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewProtoWriter(&buf, "fruit.v1").WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	expected := `// Generated from the Fruit API blueprint.
+
+syntax = "proto3";
+
+package fruit.v1;
+
+import "google/protobuf/struct.proto";
+
+message Dimension {
+  optional string radius = 1;
+  optional DimensionUnit unit = 2;
+}
+
+enum DimensionUnit {
+  DIMENSION_UNIT_UNSPECIFIED = 0;
+  DIMENSION_UNIT_CM = 1;
+  DIMENSION_UNIT_INCH = 2;
+}
+
+// Something to eat.
+message Produce {
+  optional string radius = 1001;
+  optional DimensionUnit unit = 1002;
+  // What colour is it?
+  string colour = 1;
+  repeated ProduceSeeds seeds = 2;
+  optional string content_type = 3 [json_name = "content-type"];
+  optional Grade grade = 4;
+  repeated string tags = 5;
+  google.protobuf.Struct data = 6;
+  oneof one_of {
+    string barcode = 7;
+    ProduceLot lot = 8;
+  }
+}
+
+message ProduceLot {
+  optional string lot = 1;
+}
+
+message ProduceSeeds {
+  optional string weight = 1;
+}
+
+enum Grade {
+  GRADE_UNSPECIFIED = 0;
+  GRADE_PREMIUM = 1;
+  GRADE_STANDARD = 2;
+}
+`
+
+	if buf.String() != expected {
+		t.Errorf("WriteDoc() =\n%v\nwant:\n%v", buf.String(), expected)
+	}
+}
+
+func Test_ProtoWriter_WriteDoc_should_keep_numbers_when_a_base_grows(t *testing.T) {
+	t.Parallel()
+
+	dataTable := []string{"", "+ updated (string)\n"}
+
+	for i, added := range dataTable {
+		doc, err := Parse("fruit.apib", `# API
+## Data Structures
+### Base
++ id (string)
+`+added+`
+### Dimension (Base)
++ radius (number)
+
+### Produce (Dimension)
++ colour (string)
++ One Of
+    + barcode (string)
+    + lot (number)`)
+		if err != nil {
+			t.Fatalf("[%v] err = %v, want nil", i, err)
+		}
+
+		var buf bytes.Buffer
+		if err := NewProtoWriter(&buf, "").WriteDoc(doc); err != nil {
+			t.Fatalf("[%v] err = %v, want nil", i, err)
+		}
+
+		expected := []string{
+			"optional string id = 2001;",
+			"optional string radius = 1001;",
+			"optional string colour = 1;",
+			"string barcode = 2;",
+			"string lot = 3;",
+		}
+		if added != "" {
+			expected = append(expected, "optional string updated = 2002;")
+		}
+
+		for j, e := range expected {
+			if !strings.Contains(buf.String(), e) {
+				t.Errorf("[%v][%v] missing %q in:\n%v", i, j, e, buf.String())
+			}
+		}
+	}
+}
+
+func Test_ProtoWriter_WriteDoc_should_keep_numbers_when_an_include_grows(t *testing.T) {
+	t.Parallel()
+
+	dataTable := []string{"", "+ updated (string)\n"}
+
+	for i, added := range dataTable {
+		doc, err := Parse("fruit.apib", `# API
+## Data Structures
+### Timestamps
++ created (string)
+`+added+`
+### Audit
++ by (string)
+
+### Notes
++ note (string)
+
+### Base
++ Include Audit
+
+### Order (Base)
++ Include Timestamps
++ id (string)
++ Include Notes
++ total (number)`)
+		if err != nil {
+			t.Fatalf("[%v] err = %v, want nil", i, err)
+		}
+
+		var buf bytes.Buffer
+		if err := NewProtoWriter(&buf, "").WriteDoc(doc); err != nil {
+			t.Fatalf("[%v] err = %v, want nil", i, err)
+		}
+
+		expected := []string{
+			"optional string by = 1901;",
+			"optional string created = 901;",
+			"optional string id = 1;",
+			"optional string note = 801;",
+			"optional string total = 2;",
+		}
+		if added != "" {
+			expected = append(expected, "optional string updated = 902;")
+		}
+
+		for j, e := range expected {
+			if !strings.Contains(buf.String(), e) {
+				t.Errorf("[%v][%v] missing %q in:\n%v", i, j, e, buf.String())
+			}
+		}
+	}
+}
+
+func Test_ProtoWriter_WriteDoc_should_configure_numbers(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("fruit.apib", protoDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	w := NewProtoWriter(&buf, "")
+	w.Numbers = "double"
+	if err := w.WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	if !bytes.Contains(buf.Bytes(), []byte("optional double radius = 1;")) {
+		t.Errorf("missing double radius in:\n%v", buf.String())
+	}
+	if bytes.Contains(buf.Bytes(), []byte("package")) {
+		t.Errorf("unexpected package in:\n%v", buf.String())
+	}
+
+	w.Numbers = "int"
+	if err := w.WriteDoc(doc); err == nil {
+		t.Errorf("err = nil, want unknown number type")
+	}
+}
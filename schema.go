@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// schema is a JSON Schema in the OpenAPI dialect, see draft2020 for the
+// conversion to JSON Schema draft 2020-12.
+type schema struct {
+	Schema      string        `json:"$schema,omitempty"`
+	ID          string        `json:"$id,omitempty"`
+	Ref         string        `json:"$ref,omitempty"`
+	Title       string        `json:"title,omitempty"`
+	Type        interface{}   `json:"type,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
+	Items       *schema       `json:"items,omitempty"`
+	Properties  orderedMap    `json:"properties,omitempty"`
+	Required    []string      `json:"required,omitempty"`
+	AllOf       []*schema     `json:"allOf,omitempty"`
+	OneOf       []*schema     `json:"oneOf,omitempty"`
+	AnyOf       []*schema     `json:"anyOf,omitempty"`
+	Nullable    bool          `json:"nullable,omitempty"`
+	Default     interface{}   `json:"default,omitempty"`
+	Example     interface{}   `json:"example,omitempty"`
+	Examples    []interface{} `json:"examples,omitempty"`
+}
+
+// orderedMap is a JSON object that keeps the order its members were added.
+type orderedMap []member
+
+type member struct {
+	Key   string
+	Value interface{}
+}
+
+// set adds or replaces the member key.
+func (m *orderedMap) set(key string, value interface{}) {
+	for i := range *m {
+		if (*m)[i].Key == key {
+			(*m)[i].Value = value
+			return
+		}
+	}
+	*m = append(*m, member{key, value})
+}
+
+// get returns the value of the member key or nil.
+func (m orderedMap) get(key string) interface{} {
+	for _, kv := range m {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return nil
+}
+
+func (m orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, kv := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(kv.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// schemaBuilder converts data structures and properties into schemas,
+// named types are referenced with the URI returned by refURI.
+type schemaBuilder struct {
+	doc    *Document
+	refURI func(name string) string
+}
+
+// dataStructure returns the schema of ds without its description.
+func (b *schemaBuilder) dataStructure(ds *DataStructure) *schema {
+	switch {
+	case ds.IsTypeEnum():
+		return &schema{OneOf: b.typeRefs(ds.Properties)}
+	case ds.IsEnum:
+		return enumSchema(ds.Type, ds.Properties)
+	case ds.IsArray:
+		return &schema{Type: "array", Items: b.typeSchema(ds.Type)}
+	case isPrimitive(ds.Type):
+		return &schema{Type: ds.Type}
+	}
+
+	obj := b.object(ds.Properties)
+	base := ds.Base()
+	switch {
+	case base == "":
+		return obj
+	case len(obj.Properties) == 0 && len(obj.OneOf) == 0:
+		return b.ref(base)
+	}
+	return &schema{AllOf: []*schema{b.ref(base), obj}}
+}
+
+// object returns an object schema with properties, One Of sections become
+// oneOf alternatives.
+func (b *schemaBuilder) object(properties []*Property) *schema {
+	s := &schema{Type: "object"}
+	for _, property := range b.doc.Expand(properties) {
+		if property.OneOf {
+			for _, alt := range property.Properties {
+				s.OneOf = append(s.OneOf, b.object(alternativeMembers(alt)))
+			}
+			continue
+		}
+
+		s.Properties.set(property.Name, b.property(property))
+		if property.Required {
+			s.Required = append(s.Required, property.Name)
+		}
+	}
+	return s
+}
+
+// property returns the schema of property including its description, sample
+// value and attributes.
+func (b *schemaBuilder) property(property *Property) *schema {
+	var s *schema
+	switch {
+	case property.IsInline():
+		s = b.object(property.Properties)
+	case property.IsTypeEnum():
+		s = &schema{OneOf: b.typeRefs(property.Properties)}
+	case property.IsEnum:
+		s = enumSchema(property.Type, property.Properties)
+	case property.IsArray:
+		items := b.typeSchema(property.Type)
+		if property.Type == "" && len(property.Properties) > 0 {
+			items = b.object(property.Properties)
+		}
+		s = &schema{Type: "array", Items: items}
+	default:
+		s = b.typeSchema(property.Type)
+	}
+
+	// siblings of $ref are ignored, the reference is wrapped instead.
+	if s.Ref != "" && (property.Description != "" || property.Nullable) {
+		s = &schema{AllOf: []*schema{s}}
+	}
+
+	s.Description = property.Description
+	s.Nullable = property.Nullable
+	if property.Value != "" {
+		value := sampleValue(property)
+		if property.Default {
+			s.Default = value
+		} else {
+			s.Example = value
+		}
+	}
+
+	return s
+}
+
+// typeSchema returns the schema of an MSON base type or a reference to a
+// named type.
+func (b *schemaBuilder) typeSchema(t string) *schema {
+	switch {
+	case t == "" || t == "string":
+		return &schema{Type: "string"}
+	case isPrimitive(t) || t == "object" || t == "array":
+		return &schema{Type: t}
+	}
+	return b.ref(t)
+}
+
+func (b *schemaBuilder) ref(name string) *schema {
+	return &schema{Ref: b.refURI(name)}
+}
+
+// typeRefs returns a reference for each member of an enum of named types.
+func (b *schemaBuilder) typeRefs(members []*Property) []*schema {
+	var refs []*schema
+	for _, member := range members {
+		refs = append(refs, b.ref(member.Type))
+	}
+	return refs
+}
+
+// enumSchema returns the schema of an enum with members of type t.
+func enumSchema(t string, members []*Property) *schema {
+	if !isPrimitive(t) {
+		t = "string"
+	}
+
+	s := &schema{Type: t}
+	for _, member := range members {
+		s.Enum = append(s.Enum, primitiveValue(t, member.Name))
+	}
+	return s
+}
+
+// isPrimitive reports whether t is an MSON primitive type.
+func isPrimitive(t string) bool {
+	_, ok := primitives[t]
+	return ok
+}
+
+// sampleValue converts the value of property to its JSON type, the values of
+// an array are separated by commas.
+func sampleValue(property *Property) interface{} {
+	value := strings.Trim(property.Value, "`")
+	if !property.IsArray {
+		return primitiveValue(property.Type, value)
+	}
+
+	values := []interface{}{}
+	for _, v := range strings.Split(value, ",") {
+		values = append(values, primitiveValue(property.Type, strings.TrimSpace(v)))
+	}
+	return values
+}
+
+// primitiveValue converts value to a number or boolean when t is one and the
+// value is valid, otherwise it is a string.
+func primitiveValue(t, value string) interface{} {
+	switch t {
+	case "number":
+		if json.Valid([]byte(value)) && strings.Trim(value, "-0123456789.eE+") == "" {
+			return json.Number(value)
+		}
+	case "boolean":
+		if value == "true" || value == "false" {
+			return value == "true"
+		}
+	}
+	return value
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// writeServer writes a Server interface with a method for each action of doc
+// and NewHandler, which routes requests by method and URI template, decodes
+// the request attributes and encodes the result with the status code of the
+// first 2xx response.
+func (w *GoWriter) writeServer(doc *Document, endpoints []*endpoint) {
+	w.use("context", "")
+	w.use("net/http", "")
+	w.use(apibImport, "")
+
+	api := "the API"
+	if doc.Title != "" {
+		api = "the " + doc.Title
+	}
+
+	w.Write(bs("// Server implements the actions of %v. Return an *apib.StatusError to\n", api))
+	w.Write(bs("// respond with a status code other than the documented one.\n"))
+	w.Write(bs("type Server interface {\n"))
+	for _, e := range endpoints {
+		w.Write(bs("\t// %v handles %v %v.\n", e.Name, e.Method, e.URITemplate))
+		w.Write(bs("\t%v(%v) %v\n", e.Name, strings.Join(serverParams(e), ", "), serverResults(e)))
+	}
+	w.Write(bs("}\n\n"))
+
+	w.Write(bs("// NewHandler returns an http.Handler that routes the requests of %v to s.\n", api))
+	w.Write(bs("func NewHandler(s Server) http.Handler {\n"))
+	w.Write(bs("\tr := &apib.Router{}\n"))
+	for _, e := range endpoints {
+		w.writeRoute(e)
+	}
+	w.Write(bs("\treturn r\n"))
+	w.Write(bs("}\n\n"))
+}
+
+// serverParams are the parameters of the Server method for e.
+func serverParams(e *endpoint) []string {
+	params := []string{"ctx context.Context"}
+	for _, p := range e.Params {
+		params = append(params, p+" string")
+	}
+	if e.Request != "" {
+		params = append(params, "body "+e.Request)
+	}
+	return params
+}
+
+// serverResults are the results of the Server method for e.
+func serverResults(e *endpoint) string {
+	if e.Response == "" {
+		return "error"
+	}
+	return fmt.Sprintf("(%v, error)", e.Response)
+}
+
+// writeRoute registers the handler that calls the Server method for e.
+func (w *GoWriter) writeRoute(e *endpoint) {
+	w.Write(bs("\tr.Handle(%q, %q, func(w http.ResponseWriter, req *http.Request, vars map[string]string) {\n", e.Method, e.URITemplate))
+
+	args := []string{"req.Context()"}
+	for _, v := range e.Vars {
+		args = append(args, fmt.Sprintf("vars[%q]", v))
+	}
+
+	switch {
+	case e.Request == "":
+	case strings.HasPrefix(e.Request, "*"):
+		w.Write(bs("\t\tbody := new(%v)\n", e.Request[1:]))
+		w.Write(bs("\t\tif !apib.Decode(w, req, body) {\n"))
+		w.Write(bs("\t\t\treturn\n"))
+		w.Write(bs("\t\t}\n"))
+		args = append(args, "body")
+	default:
+		w.Write(bs("\t\tvar body %v\n", e.Request))
+		w.Write(bs("\t\tif !apib.Decode(w, req, &body) {\n"))
+		w.Write(bs("\t\t\treturn\n"))
+		w.Write(bs("\t\t}\n"))
+		args = append(args, "body")
+	}
+
+	call := fmt.Sprintf("s.%v(%v)", e.Name, strings.Join(args, ", "))
+	if e.Response == "" {
+		w.Write(bs("\t\terr := %v\n", call))
+		w.Write(bs("\t\tapib.Respond(w, %v, nil, err)\n", e.Status))
+	} else {
+		w.Write(bs("\t\tout, err := %v\n", call))
+		w.Write(bs("\t\tapib.Respond(w, %v, out, err)\n", e.Status))
+	}
+	w.Write(bs("\t})\n"))
+}
@@ -0,0 +1,56 @@
+package main_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+func Test_GoWriter_WriteDoc_should_write_server(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("notes.apib", clientDoc)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	w := NewGoWriter(&buf, "notes")
+	w.Client = true
+	w.Server = true
+	if err := w.WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+	src := buf.String()
+
+	expected := []string{
+		`type Server interface {
+	// GetHealth handles GET /health.
+	GetHealth(ctx context.Context) error`,
+		"ListNotes(ctx context.Context, limit string) ([]*Note, error)",
+		"CreateANote(ctx context.Context, limit string, body *CreateANoteRequest) (*Note, error)",
+		"DeleteNote(ctx context.Context, id string) error",
+		"func NewHandler(s Server) http.Handler {",
+		`r.Handle("POST", "/notes{?limit}", func(w http.ResponseWriter, req *http.Request, vars map[string]string) {
+		body := new(CreateANoteRequest)
+		if !apib.Decode(w, req, body) {
+			return
+		}
+		out, err := s.CreateANote(req.Context(), vars["limit"], body)
+		apib.Respond(w, 201, out, err)
+	})`,
+		`err := s.DeleteNote(req.Context(), vars["id"])
+		apib.Respond(w, 204, nil, err)`,
+	}
+
+	for i, e := range expected {
+		if !containsGo(src, e) {
+			t.Errorf("[%v] missing %q in:\n%v", i, e, src)
+		}
+	}
+
+	if n := bytes.Count(buf.Bytes(), []byte("type CreateANoteRequest struct")); n != 1 {
+		t.Errorf("CreateANoteRequest declared %v times, want 1", n)
+	}
+}
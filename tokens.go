@@ -1,5 +1,7 @@
 package main
 
+import "strings"
+
 const (
 	ItemError ItemType = iota
 
@@ -16,13 +18,39 @@ const (
 	ItemTitleLevel5
 	ItemTitleLevel6
 
+	// API blueprint sections
+	ItemGroup       // Resource group name.
+	ItemResource    // Resource name.
+	ItemAction      // Action name.
+	ItemURITemplate // URI template of a resource or action, e.g. /notes/{id}.
+	ItemHTTPMethod  // HTTP request method of an action.
+
+	// Request and response sections of an action
+	ItemRequest        // + Request keyword.
+	ItemRequestName    // Optional identifier of a request.
+	ItemResponse       // + Response keyword.
+	ItemStatusCode     // HTTP status code of a response.
+	ItemMediaType      // Media type in parenthesis, e.g. application/json.
+	ItemPayloadSection // Headers, Body, Schema, Attributes or Parameters.
+	ItemAsset          // Indented block below a payload section.
+
 	// Data structures section
 	ItemDataStructures // Section Title
 	ItemModel
+	ItemModelType
+	ItemModelDesc
+	ItemTypeSection // Members, Properties or Items.
+	ItemInclude     // Named type mixed in with Include.
+	ItemOneOf       // Mutually exclusive member list.
 	ItemPropertyName
 	ItemPropertyType
 	ItemPropertyArrayType
+	ItemPropertyEnumType
+	ItemPropertyAttr
+	ItemPropertyValue
 	ItemPropertyDesc
+	ItemIndent // Member list nested deeper than the previous member.
+	ItemDedent // Member list closed.
 )
 
 // LexMetaKey scans the Meta Section for the key in a key-value pair.
@@ -39,8 +67,7 @@ func LexMetaKey(l *Lexer) StateFn {
 		return LexMetaValue
 	}
 
-	l.Errorf("not valid meta key.")
-	return nil
+	return l.Errorf("not valid meta key.")
 }
 
 // LexMetaValue scans the Meta Section for the value in a key-value pair.
@@ -60,11 +87,34 @@ func LexMetaValue(l *Lexer) StateFn {
 	return LexMetaKey
 }
 
+// titleLevels are the items of the Markdown header levels.
+var titleLevels = []ItemType{
+	ItemTitleLevel1,
+	ItemTitleLevel2,
+	ItemTitleLevel3,
+	ItemTitleLevel4,
+	ItemTitleLevel5,
+	ItemTitleLevel6,
+}
+
+// isSectionTitle reports whether the input continues with a header, a line
+// starting with one to six #.
+func isSectionTitle(l *Lexer) bool {
+	rest := l.input[l.pos:]
+	level := len(rest) - len(strings.TrimLeft(rest, "#"))
+	return level > 0 && level <= len(titleLevels)
+}
+
 // LexSectionTitle lexes the section title.
 func LexSectionTitle(l *Lexer) StateFn {
 	start := l.Pos()
 	l.AcceptRun("#")
 	diff := l.Pos() - start
+	if diff > len(titleLevels) {
+		// more than six # is Markdown text rather than a header.
+		l.pos = start
+		return LexOverview
+	}
 
 	// consume WS
 	l.AcceptRun(" ")
@@ -73,41 +123,125 @@ func LexSectionTitle(l *Lexer) StateFn {
 	// consume to EOL
 	l.AcceptUntil("\r\n")
 
-	if l.Peek() == EOF {
-		return nil
-	}
+	l.inMeta = false
+	l.inResource = false
 
 	switch {
 	case l.HasPrefix("Data Structures"):
+		l.inDataStructures = true
+		l.dsLevel = diff
 		l.Emit(ItemDataStructures)
 		l.AcceptClasses(Whitespace)
 		l.Ignore()
+		if l.Peek() == '#' {
+			return lexHeader(l)
+		}
 		return LexModel
+	case !lexResourceTitle(l):
+		l.Emit(titleLevels[diff-1])
 	}
 
-	titles := []ItemType{
-		ItemTitleLevel1,
-		ItemTitleLevel2,
-		ItemTitleLevel3,
-		ItemTitleLevel4,
-		ItemTitleLevel5,
-		ItemTitleLevel6,
-	}
-	d := titles[diff-1]
-
-	l.Emit(d)
-
 	l.AcceptClasses(Whitespace)
 	l.Ignore()
 
 	r := l.Peek()
 	if r == '#' {
 		return LexSectionTitle
+	} else if r == EOF {
+		return nil
+	} else if l.inResource && isPayloadItem(l) {
+		return LexPayloadSection
 	}
 
 	return LexOverview
 }
 
+// lexResourceTitle emits the items of a group, resource or action header:
+//
+//	Group Notes
+//	Note [/notes/{id}]
+//	Delete a Note [DELETE]
+//	Archive [POST /notes/{id}/archive]
+//	/notes/{id}
+//	GET /notes/{id}
+//
+// It reports false when the header is a plain title.
+func lexResourceTitle(l *Lexer) bool {
+	start, end := l.start, l.pos
+	title := strings.TrimRight(l.input[start:end], " \t")
+
+	if strings.HasPrefix(title, "Group ") {
+		l.emitSpan(ItemGroup, start+len("Group "), start+len(title))
+		return true
+	}
+
+	name, spec := "", title
+	bracketed := false
+	if i := strings.LastIndex(title, "["); i >= 0 && strings.HasSuffix(title, "]") {
+		name = strings.TrimRight(title[:i], " \t")
+		spec = title[i+1 : len(title)-1]
+		start += i + 1
+		bracketed = true
+	}
+
+	method := spec
+	if i := strings.IndexAny(spec, " \t"); i >= 0 {
+		method = spec[:i]
+	}
+	uri := strings.TrimLeft(spec[len(method):], " \t")
+	uriStart := start + len(spec) - len(uri)
+
+	switch {
+	case httpMethods[method] && (uri == "" && bracketed || strings.HasPrefix(uri, "/")):
+		l.inResource = true
+		l.emitSpan(ItemAction, l.start, l.start+len(name))
+		l.emitSpan(ItemHTTPMethod, start, start+len(method))
+		if uri != "" {
+			l.emitSpan(ItemURITemplate, uriStart, uriStart+len(uri))
+		}
+	case strings.HasPrefix(spec, "/") && !strings.ContainsAny(spec, " \t"):
+		l.inResource = true
+		l.emitSpan(ItemResource, l.start, l.start+len(name))
+		l.emitSpan(ItemURITemplate, start, start+len(spec))
+	default:
+		return false
+	}
+
+	l.start, l.pos = end, end
+	return true
+}
+
+// httpMethods are the request methods recognised in action headers.
+var httpMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"OPTIONS": true,
+	"TRACE":   true,
+	"CONNECT": true,
+}
+
+// lexHeader selects the state for the header at the current position. In the
+// Data Structures section headers nested below the section title are models
+// and any other header ends the section.
+func lexHeader(l *Lexer) StateFn {
+	rest := l.input[l.pos:]
+	level := len(rest) - len(strings.TrimLeft(rest, "#"))
+	if l.inDataStructures && level > len(titleLevels) {
+		return LexModelDesc
+	}
+	if l.inDataStructures && level > l.dsLevel {
+		return LexModel
+	}
+
+	l.inDataStructures = false
+	l.inAttributes = false
+	return LexSectionTitle
+}
+
 // LexOverview scans the for the Overview body.
 func LexOverview(l *Lexer) StateFn {
 	for {
@@ -115,17 +249,242 @@ func LexOverview(l *Lexer) StateFn {
 		l.AcceptUntil("\n")
 
 		// consume WS
-		l.AcceptRun("\r\n ")
+		l.AcceptRun("\r\n\t ")
 
 		// peek if next line is a section title
 		r := l.Peek()
-		if r == '#' || r == EOF {
+		if r == '#' && isSectionTitle(l) || r == EOF || l.inResource && isPayloadItem(l) {
 			break
 		}
 	}
 	l.Emit(ItemOverview)
 
-	return LexSectionTitle
+	switch l.Peek() {
+	case EOF:
+		return nil
+	case '#':
+		return LexSectionTitle
+	}
+
+	return LexPayloadSection
+}
+
+// payloadSections are the list items recognised below a resource or action.
+// Model is skipped.
+var payloadSections = map[string]bool{
+	"Request":    true,
+	"Response":   true,
+	"Headers":    true,
+	"Body":       true,
+	"Schema":     true,
+	"Attributes": true,
+	"Parameters": true,
+	"Model":      true,
+}
+
+// isPayloadItem reports whether the line at l.pos is a payload section such
+// as + Response 200 rather than a list in a description.
+func isPayloadItem(l *Lexer) bool {
+	line := strings.TrimLeft(l.input[l.pos:], " \t")
+	if !strings.HasPrefix(line, "+") {
+		return false
+	}
+
+	line = strings.TrimLeft(line[1:], " \t")
+	i := strings.IndexFunc(line, func(r rune) bool { return !Letter(r) })
+	if i < 0 {
+		i = len(line)
+	}
+
+	return payloadSections[line[:i]]
+}
+
+// LexPayloadSection scans the list items below a resource or action, e.g.
+// + Request, + Response, + Headers, + Body, + Schema and + Attributes.
+func LexPayloadSection(l *Lexer) StateFn {
+	indent := l.position(l.pos).Column - 1
+	if !isPayloadItem(l) {
+		// payload descriptions and unknown sections are not kept.
+		skipBlock(l, indent, false)
+		return lexPayloadTail(l)
+	}
+
+	// consume + and WS
+	l.Accept("+")
+	l.AcceptRun("\t ")
+	l.Ignore()
+
+	l.AcceptClasses(Letter)
+	switch keyword := l.input[l.start:l.pos]; keyword {
+	case "Request", "Response":
+		l.Emit(map[string]ItemType{"Request": ItemRequest, "Response": ItemResponse}[keyword])
+		l.AcceptRun(" \t")
+		l.Ignore()
+
+		l.AcceptUntil("(\r\n")
+		name := strings.TrimRight(l.input[l.start:l.pos], " \t")
+		switch {
+		case keyword == "Response" && name == "":
+			return l.Errorf("missing status code in response")
+		case keyword == "Response":
+			l.emitSpan(ItemStatusCode, l.start, l.start+len(name))
+		case name != "":
+			l.emitSpan(ItemRequestName, l.start, l.start+len(name))
+		}
+		l.AcceptUntil("(\r\n")
+		l.Ignore()
+
+		if l.Accept("(") {
+			l.AcceptRun(" \t")
+			l.Ignore()
+			l.AcceptUntil(")\r\n")
+			l.Emit(ItemMediaType)
+			if !l.Accept(")") {
+				return l.Errorf("missing closing parenthesis in media type")
+			}
+		}
+
+		return lexAsset(l, indent)
+
+	case "Headers", "Body", "Schema":
+		if !endOfLine(l) {
+			return l.Errorf("unexpected text after %v", keyword)
+		}
+		l.Emit(ItemPayloadSection)
+		return lexAsset(l, indent)
+
+	case "Attributes":
+		l.Emit(ItemPayloadSection)
+		l.AcceptRun(" \t")
+		l.Ignore()
+		l.attrIndent = indent
+		if l.Peek() == '(' {
+			return LexAttributesType
+		}
+		return lexAttributesTail(l)
+
+	case "Parameters":
+		if !endOfLine(l) {
+			return l.Errorf("unexpected text after %v", keyword)
+		}
+		l.Emit(ItemPayloadSection)
+		l.attrIndent = indent
+		return lexAttributesTail(l)
+	}
+
+	// skip Model with everything nested below it.
+	skipBlock(l, indent, false)
+	return lexPayloadTail(l)
+}
+
+// LexAttributesType scans the type of an attributes section, e.g. (Note).
+func LexAttributesType(l *Lexer) StateFn {
+	// consume and ignore (
+	l.Accept("(")
+	l.AcceptRun(" \t")
+	l.Ignore()
+
+	l.AcceptClasses(Letter, Number, RuneSet("[]_-. "))
+	l.emitSpan(ItemModelType, l.start, l.start+len(strings.TrimRight(l.input[l.start:l.pos], " ")))
+
+	// attributes such as required are not used.
+	l.AcceptUntil(")\r\n")
+	if !l.Accept(")") {
+		return l.Errorf("missing closing parenthesis in attributes type")
+	}
+
+	return lexAttributesTail(l)
+}
+
+// lexAttributesTail lexes the members nested below an attributes or
+// parameters section as properties.
+func lexAttributesTail(l *Lexer) StateFn {
+	l.inAttributes = true
+	l.indents = l.indents[:0]
+
+	l.AcceptClasses(Whitespace)
+	l.Ignore()
+
+	switch l.Peek() {
+	case EOF:
+		return nil
+	case '#':
+		return lexHeader(l)
+	}
+
+	return lexMember(l)
+}
+
+// lexMember selects the state for the list item at l.pos, members of an
+// attributes section end at the first item that is not nested below it.
+func lexMember(l *Lexer) StateFn {
+	if l.inAttributes && l.position(l.pos).Column-1 <= l.attrIndent {
+		l.inAttributes = false
+		return LexPayloadSection
+	}
+
+	return LexPropertyName
+}
+
+// lexAsset emits the lines indented below the list item at indent as an
+// asset, e.g. the body of a response.
+func lexAsset(l *Lexer, indent int) StateFn {
+	l.AcceptUntil("\n")
+	l.Accept("\n")
+	l.Ignore()
+
+	if start, end := skipBlock(l, indent, true); start < end {
+		l.emitSpan(ItemAsset, start, end)
+	}
+
+	return lexPayloadTail(l)
+}
+
+// skipBlock advances past the lines that are blank or indented deeper than
+// indent, when items is true a list item ends the block. It returns the span
+// from the first to the last non-blank line.
+func skipBlock(l *Lexer, indent int, items bool) (start, end int) {
+	start, end = -1, -1
+	for l.pos < len(l.input) {
+		line := l.input[l.pos:]
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i+1]
+		}
+
+		// the first line of a skipped block is the item itself.
+		text := strings.TrimLeft(line, " \t")
+		blank := strings.TrimSpace(text) == ""
+		nested := len(line)-len(text) > indent && !(items && strings.HasPrefix(text, "+"))
+		if !blank && !nested && (items || start >= 0) {
+			break
+		}
+
+		if !blank {
+			if start < 0 {
+				start = l.pos
+			}
+			end = l.pos + len(strings.TrimRight(line, "\r\n"))
+		}
+		l.pos += len(line)
+	}
+
+	return start, end
+}
+
+// lexPayloadTail consumes the WS after a payload section and selects the next
+// state.
+func lexPayloadTail(l *Lexer) StateFn {
+	l.AcceptClasses(Whitespace)
+	l.Ignore()
+
+	switch l.Peek() {
+	case EOF:
+		return nil
+	case '#':
+		return lexHeader(l)
+	}
+
+	return LexPayloadSection
 }
 
 // LexModel scans for a models name.
@@ -139,29 +498,139 @@ func LexModel(l *Lexer) StateFn {
 	// consume to EOL, WS or right-parenthesis.
 	l.AcceptUntil("\r\n (")
 
+	l.indents = l.indents[:0]
 	l.Emit(ItemModel)
+
+	l.AcceptRun(" \t")
+	if l.Peek() == '(' {
+		l.Ignore()
+		return LexModelType
+	}
+
+	return lexModelTail(l)
+}
+
+// LexModelType scans for a models base type, e.g. enum[string].
+func LexModelType(l *Lexer) StateFn {
+	// consume and ignore (
+	l.Accept("(")
+	l.AcceptRun(" \t")
+	l.Ignore()
+
+	l.AcceptClasses(Letter, Number, RuneSet("[]_-"))
+	if l.Pos() == l.start {
+		return l.Errorf("unexpected character %q for model type", l.Peek())
+	}
+	l.Emit(ItemModelType)
+
+	// attributes on a model are not used.
+	l.AcceptUntil(")\r\n")
+	if !l.Accept(")") {
+		return l.Errorf("missing closing parenthesis in model type")
+	}
+
+	return lexModelTail(l)
+}
+
+// lexModelTail consumes the WS after a model header and selects the next state.
+func lexModelTail(l *Lexer) StateFn {
 	l.AcceptClasses(Whitespace)
 	l.Ignore()
 
+	r := l.Peek()
+	if r == '#' {
+		return lexHeader(l)
+	} else if r == EOF {
+		return nil
+	} else if r != '+' {
+		return LexModelDesc
+	}
+
 	return LexPropertyName
 }
 
+// LexModelDesc scans for the description paragraphs below a model header.
+func LexModelDesc(l *Lexer) StateFn {
+	for {
+		l.AcceptUntil("\n")
+		l.AcceptClasses(Whitespace)
+
+		r := l.Peek()
+		if r == '+' || r == '#' && isSectionTitle(l) || r == EOF {
+			break
+		}
+	}
+	l.Emit(ItemModelDesc)
+
+	return lexModelTail(l)
+}
+
 // LexPropertyName scans for a properties name.
 func LexPropertyName(l *Lexer) StateFn {
+	lexIndent(l)
+
 	// consume + and WS
 	l.Accept("+")
 	l.AcceptRun("\t ")
 	l.Ignore()
 
+	if strings.HasPrefix(l.input[l.pos:], "One Of") {
+		l.pos += len("One Of")
+		if endOfLine(l) {
+			l.Emit(ItemOneOf)
+			return lexPropertyTail(l)
+		}
+		l.pos = l.start
+	}
+
+	if l.Peek() == '`' {
+		return LexEscapedName
+	}
+
 	// capture everything until WS or :
-	l.AcceptClasses(Letter, Number)
+	l.AcceptClasses(Letter, Number, RuneSet("_-."))
 	r := l.Peek()
-	if !(r == ':' || r == ' ') {
-		l.Errorf("unexpected character `%v`:0x%v for property name", string(r), r)
-		return nil
+	if r == '(' && l.pos == l.start {
+		// a type without a name, e.g. an enum member (Card).
+		l.Emit(ItemPropertyName)
+		return LexPropertyType
+	}
+	if !(r == ':' || r == ' ' || r == '\r' || r == '\n' || r == EOF) {
+		return l.Errorf("unexpected character %q for property name", r)
+	}
+	if typeSections[l.input[l.start:l.pos]] && endOfLine(l) {
+		l.Emit(ItemTypeSection)
+		return lexPropertyTail(l)
+	}
+
+	if l.input[l.start:l.pos] == "Include" && isInclude(l) {
+		return LexInclude
 	}
+
 	l.Emit(ItemPropertyName)
 
+	return lexPropertyNameTail(l)
+}
+
+// LexEscapedName scans for a property name escaped with backticks, such as
+// `first name`.
+func LexEscapedName(l *Lexer) StateFn {
+	l.Accept("`")
+	l.Ignore()
+
+	l.AcceptUntil("`\r\n")
+	if l.Peek() != '`' {
+		return l.Errorf("missing closing backtick in property name")
+	}
+	l.Emit(ItemPropertyName)
+	l.Accept("`")
+	l.Ignore()
+
+	return lexPropertyNameTail(l)
+}
+
+// lexPropertyNameTail selects the state that follows a property name.
+func lexPropertyNameTail(l *Lexer) StateFn {
 	if l.Accept(":") {
 		return LexPropertyExample
 	}
@@ -170,14 +639,42 @@ func LexPropertyName(l *Lexer) StateFn {
 	l.AcceptRun(" \t")
 	l.Ignore()
 
-	return LexPropertyType
+	if l.Peek() == '(' {
+		return LexPropertyType
+	}
+
+	return lexPropertyTail(l)
 }
 
 // LexPropertyExample scans for a property example.
 func LexPropertyExample(l *Lexer) StateFn {
-	l.AcceptUntil("(")
+	l.AcceptRun(" \t")
 	l.Ignore()
-	return LexPropertyType
+
+	// the value runs to the type, description or EOL less trailing WS.
+	for {
+		l.AcceptUntil(" \t(\r\n")
+		end := l.Pos()
+		l.AcceptRun(" \t")
+
+		r := l.Peek()
+		if r == '(' || r == '-' || r == '\r' || r == '\n' || r == EOF {
+			ws := l.Pos()
+			l.pos = end
+			if l.pos > l.start {
+				l.Emit(ItemPropertyValue)
+			}
+			l.pos = ws
+			l.Ignore()
+			break
+		}
+	}
+
+	if l.Peek() == '(' {
+		return LexPropertyType
+	}
+
+	return lexPropertyTail(l)
 }
 
 // LexPropertyType scans for a type.
@@ -186,42 +683,153 @@ func LexPropertyType(l *Lexer) StateFn {
 	l.Accept("(")
 	l.Ignore()
 
-	// capture letters
-	l.AcceptClasses(Letter)
+	// capture the type name
+	l.AcceptClasses(Letter, Number, RuneSet("_-"))
 	r := l.Peek()
 	if r == ',' || r == ')' {
 		l.Emit(ItemPropertyType)
 	} else if l.Accept("[") {
+		t := ItemPropertyArrayType
+		kind := l.input[l.start : l.pos-1]
+		switch kind {
+		case "array":
+		case "enum":
+			t = ItemPropertyEnumType
+		default:
+			return l.Errorf("unexpected type %v[ for property type", kind)
+		}
 		l.Ignore()
-		l.AcceptClasses(Letter)
+		l.AcceptClasses(Letter, Number, RuneSet("_-"))
 		if l.Peek() == ']' {
-			l.Emit(ItemPropertyArrayType)
+			l.Emit(t)
 			// consume ]
 			l.Next()
 		} else {
-			l.Errorf("missing closing brace in array type")
-			return nil
+			return l.Errorf("missing closing brace in %v type", kind)
 		}
 	} else {
-		l.Errorf("unexpected character 0x%v for property type", l.Peek())
-		return nil
+		return l.Errorf("unexpected character %q for property type", l.Peek())
 	}
 
-	// consume boundary and ignore WS
-	l.Accept(",)")
+	// consume boundary, attributes follow a comma.
+	if l.Accept(",") {
+		l.Ignore()
+		return LexPropertyAttr
+	}
+
+	if !l.Accept(")") {
+		return l.Errorf("unexpected character %q for property type", l.Peek())
+	}
+
+	return lexPropertyTail(l)
+}
+
+// LexPropertyAttr scans for a type attribute such as required or nullable.
+func LexPropertyAttr(l *Lexer) StateFn {
+	l.AcceptRun(" \t")
+	l.Ignore()
+
+	l.AcceptClasses(Letter, RuneSet("-"))
+	if l.Pos() == l.start {
+		return l.Errorf("unexpected character %q for type attribute", l.Peek())
+	}
+	l.Emit(ItemPropertyAttr)
+
+	l.AcceptRun(" \t")
+	l.Ignore()
+
+	if l.Accept(",") {
+		l.Ignore()
+		return LexPropertyAttr
+	}
+
+	if !l.Accept(")") {
+		return l.Errorf("unexpected character %q for type attribute", l.Peek())
+	}
+
+	return lexPropertyTail(l)
+}
+
+// LexInclude scans for the type name of an Include mixin.
+func LexInclude(l *Lexer) StateFn {
+	l.AcceptRun(" \t")
+	l.Ignore()
+
+	l.AcceptClasses(Letter, Number, RuneSet("_-"))
+	l.Emit(ItemInclude)
+
+	return lexPropertyTail(l)
+}
+
+// isInclude reports whether the Include keyword is followed by a type name
+// rather than a property type or description.
+func isInclude(l *Lexer) bool {
+	if l.Peek() != ' ' {
+		return false
+	}
+
+	rest := strings.TrimLeft(l.input[l.pos:], " \t")
+	return rest != "" && Letter(rune(rest[0]))
+}
+
+// typeSections are the MSON keywords that introduce a nested member list.
+var typeSections = map[string]bool{
+	"Members":    true,
+	"Properties": true,
+	"Items":      true,
+}
+
+// endOfLine reports whether only WS remains on the current line.
+func endOfLine(l *Lexer) bool {
+	for _, r := range l.input[l.pos:] {
+		switch r {
+		case ' ', '\t':
+			continue
+		case '\r', '\n':
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// lexIndent emits ItemIndent or ItemDedent items when the indentation of the
+// member at l.start differs from the enclosing member list.
+func lexIndent(l *Lexer) {
+	indent := l.position(l.start).Column - 1
+	n := len(l.indents)
+
+	switch {
+	case n == 0:
+		l.indents = append(l.indents, indent)
+
+	case indent > l.indents[n-1]:
+		l.indents = append(l.indents, indent)
+		l.Emit(ItemIndent)
+
+	default:
+		for ; n > 1 && indent < l.indents[n-1]; n-- {
+			l.indents = l.indents[:n-1]
+			l.Emit(ItemDedent)
+		}
+	}
+}
+
+// lexPropertyTail consumes the WS after a property and selects the next state.
+func lexPropertyTail(l *Lexer) StateFn {
 	l.AcceptClasses(Whitespace)
 	l.Ignore()
 
-	r = l.Peek()
+	r := l.Peek()
 	if r == '#' {
-		return LexModel
+		return lexHeader(l)
 	} else if r == '-' {
 		return LexPropertyDesc
 	} else if r == EOF {
 		return nil
 	}
 
-	return LexPropertyName
+	return lexMember(l)
 }
 
 func LexPropertyDesc(l *Lexer) StateFn {
@@ -233,9 +841,35 @@ func LexPropertyDesc(l *Lexer) StateFn {
 
 	r := l.Peek()
 	if r == '#' {
-		return LexModel
-	} else {
-		return LexPropertyName
+		return lexHeader(l)
+	} else if r == EOF {
+		return nil
+	}
+
+	return lexMember(l)
+}
+
+// LexRecover skips the remainder of a line that failed to lex and resumes at
+// the next list item or header.
+func LexRecover(l *Lexer) StateFn {
+	for {
+		l.AcceptUntil("\n")
+		l.AcceptClasses(Whitespace)
+		l.Ignore()
+
+		r := l.Peek()
+		switch {
+		case r == EOF:
+			return nil
+		case r == '#':
+			return lexHeader(l)
+		case r == '+' && (l.inDataStructures || l.inAttributes):
+			return lexMember(l)
+		case l.inResource:
+			return LexPayloadSection
+		case l.inMeta:
+			return LexMetaKey
+		}
 	}
 }
 
@@ -1,6 +1,7 @@
 package main_test
 
 import (
+	"reflect"
 	"testing"
 
 	. "github.com/nfisher/apib2go"
@@ -45,6 +46,11 @@ func Test_rune_classifiers(t *testing.T) {
 	}
 }
 
+// typeValue strips the position so items can be compared by content.
+func typeValue(item Item) Item {
+	return Item{Type: item.Type, Value: item.Value}
+}
+
 func lexItem(doc string, fn StateFn) (item Item, pos int) {
 	l := New("meta.apib", doc)
 	go func() {
@@ -72,8 +78,8 @@ func Test_LexMetaKey(t *testing.T) {
 			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
 		}
 
-		expected := Item{td[2].(ItemType), td[3].(string)}
-		if item != expected {
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
 			t.Errorf("[%v] item = %v, want %v", i, item, expected)
 		}
 	}
@@ -96,8 +102,8 @@ func Test_LexMetaValue(t *testing.T) {
 			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
 		}
 
-		expected := Item{td[2].(ItemType), td[3].(string)}
-		if item != expected {
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
 			t.Errorf("[%v] item = %v, want %v", i, item, expected)
 		}
 	}
@@ -126,8 +132,8 @@ func Test_LexSectionTitle(t *testing.T) {
 			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
 		}
 
-		expected := Item{td[2].(ItemType), td[3].(string)}
-		if item != expected {
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
 			t.Errorf("[%v] item = %v, want %v", i, item, expected)
 		}
 	}
@@ -151,8 +157,8 @@ func Test_LexOverview(t *testing.T) {
 			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
 		}
 
-		expected := Item{td[2].(ItemType), td[3].(string)}
-		if item != expected {
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
 			t.Errorf("[%v] item = %v, want %v", i, item, expected)
 		}
 	}
@@ -177,8 +183,59 @@ func Test_LexModel(t *testing.T) {
 			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
 		}
 
-		expected := Item{td[2].(ItemType), td[3].(string)}
-		if item != expected {
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
+			t.Errorf("[%v] item = %v, want %v", i, item, expected)
+		}
+	}
+}
+
+func Test_LexModelType(t *testing.T) {
+	t.Parallel()
+
+	// doc, pos, item, value
+	dataTable := [][]interface{}{
+		{"(object)\n", 9, ItemModelType, "object"},
+		{"(enum[string])\n", 15, ItemModelType, "enum[string]"},
+		{"( User, fixed)\n+", 15, ItemModelType, "User"},
+		{"(*)\n", 1, ItemError, "unexpected character '*' for model type"},
+	}
+
+	for i, td := range dataTable {
+		item, pos := lexItem(td[0].(string), LexModelType)
+
+		expPos := td[1].(int)
+		if expPos != pos {
+			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
+		}
+
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
+			t.Errorf("[%v] item = %v, want %v", i, item, expected)
+		}
+	}
+}
+
+func Test_LexModelDesc(t *testing.T) {
+	t.Parallel()
+
+	// doc, pos, item, value
+	dataTable := [][]interface{}{
+		{"Fruit.\n\n+ colour", 8, ItemModelDesc, "Fruit.\n\n"},
+		{"Fruit\nand veg.\n### Dimension", 15, ItemModelDesc, "Fruit\nand veg.\n"},
+		{"Fruit.", 6, ItemModelDesc, "Fruit."},
+	}
+
+	for i, td := range dataTable {
+		item, pos := lexItem(td[0].(string), LexModelDesc)
+
+		expPos := td[1].(int)
+		if expPos != pos {
+			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
+		}
+
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
 			t.Errorf("[%v] item = %v, want %v", i, item, expected)
 		}
 	}
@@ -191,7 +248,12 @@ func Test_LexPropertyName(t *testing.T) {
 	dataTable := [][]interface{}{
 		{"+ email:", 8, ItemPropertyName, "email"},
 		{"+ email ", 8, ItemPropertyName, "email"},
-		{"+ email* ", 7, ItemError, "unexpected character `*`:0x42 for property name"},
+		{"+ email* ", 7, ItemError, "unexpected character '*' for property name"},
+		{"+ active\n", 9, ItemPropertyName, "active"},
+		{"+ user_id ", 10, ItemPropertyName, "user_id"},
+		{"+ in-progress\n", 14, ItemPropertyName, "in-progress"},
+		{"+ Members\n", 10, ItemTypeSection, "Members"},
+		{"+ Members (string)\n", 10, ItemPropertyName, "Members"},
 	}
 
 	for i, td := range dataTable {
@@ -202,8 +264,8 @@ func Test_LexPropertyName(t *testing.T) {
 			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
 		}
 
-		expected := Item{td[2].(ItemType), td[3].(string)}
-		if item != expected {
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
 			t.Errorf("[%v] item = %v,\nwant %v", i, item, expected)
 		}
 	}
@@ -214,14 +276,19 @@ func Test_LexPropertyType(t *testing.T) {
 
 	// doc, pos, item, value
 	dataTable := [][]interface{}{
-		{"(number\n", 7, ItemError, "unexpected character 0x10 for property type"},
+		{"(number\n", 7, ItemError, "unexpected character '\\n' for property type"},
 		{"(number) ", 9, ItemPropertyType, "number"},
 		{"(number,required)", 8, ItemPropertyType, "number"},
 		{"(number) ", 9, ItemPropertyType, "number"},
 		{"(number)\n", 9, ItemPropertyType, "number"},
 		{"(array[number])\n", 16, ItemPropertyArrayType, "number"},
 		{"(array[number)\n", 13, ItemError, "missing closing brace in array type"},
-		{"(arraynumber])\n", 12, ItemError, "unexpected character 0x93 for property type"},
+		{"(arraynumber])\n", 12, ItemError, "unexpected character ']' for property type"},
+		{"(enum[string])\n", 15, ItemPropertyEnumType, "string"},
+		{"(map[string])\n", 5, ItemError, "unexpected type map[ for property type"},
+		{"(Dimension2)\n", 13, ItemPropertyType, "Dimension2"},
+		{"(Foo_Bar,required)", 9, ItemPropertyType, "Foo_Bar"},
+		{"(array[line-item])\n", 19, ItemPropertyArrayType, "line-item"},
 	}
 
 	for i, td := range dataTable {
@@ -232,8 +299,59 @@ func Test_LexPropertyType(t *testing.T) {
 			t.Errorf("[%v] pos = %v, want %v for %v", i, pos, expPos, item)
 		}
 
-		expected := Item{td[2].(ItemType), td[3].(string)}
-		if item != expected {
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
+			t.Errorf("[%v] item = %v, want %v", i, item, expected)
+		}
+	}
+}
+
+func Test_LexPropertyAttr(t *testing.T) {
+	t.Parallel()
+
+	// doc, pos, item, value
+	dataTable := [][]interface{}{
+		{"required)", 9, ItemPropertyAttr, "required"},
+		{" fixed-type, nullable)", 12, ItemPropertyAttr, "fixed-type"},
+		{"*)", 0, ItemError, "unexpected character '*' for type attribute"},
+	}
+
+	for i, td := range dataTable {
+		item, pos := lexItem(td[0].(string), LexPropertyAttr)
+
+		expPos := td[1].(int)
+		if expPos != pos {
+			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
+		}
+
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
+			t.Errorf("[%v] item = %v, want %v", i, item, expected)
+		}
+	}
+}
+
+func Test_LexPropertyExample(t *testing.T) {
+	t.Parallel()
+
+	// doc, pos, item, value
+	dataTable := [][]interface{}{
+		{" 123 (number)", 5, ItemPropertyValue, "123"},
+		{" hello world (string)", 13, ItemPropertyValue, "hello world"},
+		{" yellow - What colour?", 8, ItemPropertyValue, "yellow"},
+		{" yellow\n", 8, ItemPropertyValue, "yellow"},
+	}
+
+	for i, td := range dataTable {
+		item, pos := lexItem(td[0].(string), LexPropertyExample)
+
+		expPos := td[1].(int)
+		if expPos != pos {
+			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
+		}
+
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
 			t.Errorf("[%v] item = %v, want %v", i, item, expected)
 		}
 	}
@@ -256,8 +374,8 @@ func Test_LexPropertyDesc(t *testing.T) {
 			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
 		}
 
-		expected := Item{td[2].(ItemType), td[3].(string)}
-		if item != expected {
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
 			t.Errorf("[%v] item = %v, want %v", i, item, expected)
 		}
 	}
@@ -288,30 +406,31 @@ func Test_datastructure_apib_document(t *testing.T) {
 	}()
 
 	expected := []Item{
-		Item{ItemMetaKey, "Version"},
-		Item{ItemMetaValue, "1A9"},
-		Item{ItemTitleLevel1, "DS API"},
-		Item{ItemDataStructures, "Data Structures"},
-		Item{ItemModel, "Dimension"},
-		Item{ItemPropertyName, "radius"},
-		Item{ItemPropertyType, "number"},
-		Item{ItemPropertyName, "length"},
-		Item{ItemPropertyType, "number"},
-		Item{ItemPropertyDesc, "- This is a comment."},
-		Item{ItemModel, "Produce"},
-		Item{ItemPropertyName, "colour"},
-		Item{ItemPropertyType, "string"},
-		Item{ItemPropertyDesc, "- What colour is it?"},
-		Item{ItemPropertyName, "dimensions"},
-		Item{ItemPropertyType, "Dimension"},
-		Item{ItemPropertyName, "fruit"},
-		Item{ItemPropertyType, "boolean"},
-		Item{ItemPropertyDesc, "- Is it fruit?"},
+		{Type: ItemMetaKey, Value: "Version"},
+		{Type: ItemMetaValue, Value: "1A9"},
+		{Type: ItemTitleLevel1, Value: "DS API"},
+		{Type: ItemDataStructures, Value: "Data Structures"},
+		{Type: ItemModel, Value: "Dimension"},
+		{Type: ItemPropertyName, Value: "radius"},
+		{Type: ItemPropertyValue, Value: "123"},
+		{Type: ItemPropertyType, Value: "number"},
+		{Type: ItemPropertyName, Value: "length"},
+		{Type: ItemPropertyType, Value: "number"},
+		{Type: ItemPropertyDesc, Value: "- This is a comment."},
+		{Type: ItemModel, Value: "Produce"},
+		{Type: ItemPropertyName, Value: "colour"},
+		{Type: ItemPropertyType, Value: "string"},
+		{Type: ItemPropertyDesc, Value: "- What colour is it?"},
+		{Type: ItemPropertyName, Value: "dimensions"},
+		{Type: ItemPropertyType, Value: "Dimension"},
+		{Type: ItemPropertyName, Value: "fruit"},
+		{Type: ItemPropertyType, Value: "boolean"},
+		{Type: ItemPropertyDesc, Value: "- Is it fruit?"},
 	}
 
 	for i, ex := range expected {
 		item := <-l.Items
-		if item != ex {
+		if typeValue(item) != ex {
 			t.Errorf("[%v] item = %v, want %v: %s", i, item, ex, string(l.Peek()))
 		}
 	}
@@ -341,15 +460,197 @@ Overview
 
         pong`
 
-	req := `+ Request pong
+	l := New("meta.apib", doc)
 
-    + Headers
+	go func() {
+		l.Run()
+	}()
 
-            Accept: text/plain
+	expected := []Item{
+		{Type: ItemMetaKey, Value: "Version"},
+		{Type: ItemMetaValue, Value: "1A9"},
+		{Type: ItemTitleLevel1, Value: "Simple API"},
+		{Type: ItemOverview, Value: "Overview\n\n"},
+		{Type: ItemGroup, Value: "Health Check"},
+		{Type: ItemResource, Value: "Ping"},
+		{Type: ItemURITemplate, Value: "/ping"},
+		{Type: ItemAction, Value: "Ping-Pong"},
+		{Type: ItemHTTPMethod, Value: "GET"},
+		{Type: ItemRequest, Value: "Request"},
+		{Type: ItemRequestName, Value: "pong"},
+		{Type: ItemPayloadSection, Value: "Headers"},
+		{Type: ItemAsset, Value: "            Accept: text/plain"},
+		{Type: ItemResponse, Value: "Response"},
+		{Type: ItemStatusCode, Value: "200"},
+		{Type: ItemMediaType, Value: "text/plain; charset=utf-8"},
+		{Type: ItemAsset, Value: "        pong"},
+	}
 
-+ Response 200 (text/plain; charset=utf-8)
+	for i, ex := range expected {
+		item := <-l.Items
+		if typeValue(item) != ex {
+			t.Errorf("[%v] item = %v, want %v: %s", i, item, ex, string(l.Peek()))
+		}
+	}
+}
 
-        pong`
+func Test_resource_titles(t *testing.T) {
+	t.Parallel()
+
+	// doc, items
+	dataTable := [][]interface{}{
+		{"# Group Notes", []Item{{Type: ItemGroup, Value: "Notes"}}},
+		{"## Note [/notes/{id}]", []Item{{Type: ItemResource, Value: "Note"}, {Type: ItemURITemplate, Value: "/notes/{id}"}}},
+		{"## /notes", []Item{{Type: ItemResource}, {Type: ItemURITemplate, Value: "/notes"}}},
+		{"### Delete a Note [DELETE]", []Item{{Type: ItemAction, Value: "Delete a Note"}, {Type: ItemHTTPMethod, Value: "DELETE"}}},
+		{"### Archive [POST /notes/{id}/archive]", []Item{{Type: ItemAction, Value: "Archive"}, {Type: ItemHTTPMethod, Value: "POST"}, {Type: ItemURITemplate, Value: "/notes/{id}/archive"}}},
+		{"## GET /notes{?limit}", []Item{{Type: ItemAction}, {Type: ItemHTTPMethod, Value: "GET"}, {Type: ItemURITemplate, Value: "/notes{?limit}"}}},
+		{"## Notes [draft]", []Item{{Type: ItemTitleLevel2, Value: "Notes [draft]"}}},
+		{"## GET notes", []Item{{Type: ItemTitleLevel2, Value: "GET notes"}}},
+		{"### DELETE", []Item{{Type: ItemTitleLevel3, Value: "DELETE"}}},
+	}
+
+	for i, td := range dataTable {
+		l := New("titles.apib", td[0].(string))
+		go l.Run()
+
+		var items []Item
+		for item := range l.Items {
+			items = append(items, typeValue(item))
+		}
+
+		expected := td[1].([]Item)
+		if !reflect.DeepEqual(items, expected) {
+			t.Errorf("[%v] items = %v, want %v", i, items, expected)
+		}
+	}
+}
+
+func Test_resource_title_positions(t *testing.T) {
+	t.Parallel()
+
+	l := New("titles.apib", "# API\n\n## Archive [POST /notes/{id}/archive]\n")
+	go l.Run()
+
+	expected := []string{"titles.apib:1:3", "titles.apib:3:4", "titles.apib:3:13", "titles.apib:3:18"}
+	var actual []string
+	for item := range l.Items {
+		actual = append(actual, item.Pos.String())
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("positions = %v, want %v", actual, expected)
+	}
+}
+
+func Test_data_structures_end_at_outer_header(t *testing.T) {
+	t.Parallel()
+	var doc = `## Data Structures
+
+### Note
++ id: 1 (number)
+
+# Group Notes
+
+## Notes [/notes]`
+
+	l := New("ds.apib", doc)
+	go l.Run()
+
+	expected := []Item{
+		{Type: ItemDataStructures, Value: "Data Structures"},
+		{Type: ItemModel, Value: "Note"},
+		{Type: ItemPropertyName, Value: "id"},
+		{Type: ItemPropertyValue, Value: "1"},
+		{Type: ItemPropertyType, Value: "number"},
+		{Type: ItemGroup, Value: "Notes"},
+		{Type: ItemResource, Value: "Notes"},
+		{Type: ItemURITemplate, Value: "/notes"},
+	}
+
+	var items []Item
+	for item := range l.Items {
+		items = append(items, typeValue(item))
+	}
+
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("items = %v, want %v", items, expected)
+	}
+}
+
+func Test_payload_ending_in_whitespace(t *testing.T) {
+	t.Parallel()
+	var doc = "# A\n## Note [/n]\n### Get [GET]\n+ Response 200\n  "
+
+	l := New("ws.apib", doc)
+	go l.Run()
+
+	expected := []Item{
+		{Type: ItemTitleLevel1, Value: "A"},
+		{Type: ItemResource, Value: "Note"},
+		{Type: ItemURITemplate, Value: "/n"},
+		{Type: ItemAction, Value: "Get"},
+		{Type: ItemHTTPMethod, Value: "GET"},
+		{Type: ItemResponse, Value: "Response"},
+		{Type: ItemStatusCode, Value: "200"},
+	}
+
+	var items []Item
+	for item := range l.Items {
+		items = append(items, typeValue(item))
+	}
+
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("items = %v, want %v", items, expected)
+	}
+}
+
+func Test_more_than_six_hashes_are_text(t *testing.T) {
+	t.Parallel()
+	var doc = "# A\n####### not a header\n## Note [/n]\n####### x\n# Data Structures\n## B\n####### y\n+ a (string)\n"
+
+	l := New("hash.apib", doc)
+	go l.Run()
+
+	expected := []Item{
+		{Type: ItemTitleLevel1, Value: "A"},
+		{Type: ItemOverview, Value: "####### not a header\n"},
+		{Type: ItemResource, Value: "Note"},
+		{Type: ItemURITemplate, Value: "/n"},
+		{Type: ItemOverview, Value: "####### x\n"},
+		{Type: ItemDataStructures, Value: "Data Structures"},
+		{Type: ItemModel, Value: "B"},
+		{Type: ItemModelDesc, Value: "####### y\n"},
+		{Type: ItemPropertyName, Value: "a"},
+		{Type: ItemPropertyType, Value: "string"},
+	}
+
+	var items []Item
+	for item := range l.Items {
+		items = append(items, typeValue(item))
+	}
+
+	if !reflect.DeepEqual(items, expected) {
+		t.Errorf("items = %v, want %v", items, expected)
+	}
+}
+
+func Test_nested_members_apib_document(t *testing.T) {
+	t.Parallel()
+	var doc = `# DS API
+
+## Data Structures
+
+### Produce
++ colour (string)
++ dimensions (object)
+    + radius (number)
+    + core (object)
+        + size (number)
++ fruit (boolean)
+
+### Dimension
+    + radius (number)`
 
 	l := New("meta.apib", doc)
 
@@ -358,20 +659,159 @@ Overview
 	}()
 
 	expected := []Item{
-		Item{ItemMetaKey, "Version"},
-		Item{ItemMetaValue, "1A9"},
-		Item{ItemTitleLevel1, "Simple API"},
-		Item{ItemOverview, "Overview\n\n"},
-		Item{ItemTitleLevel1, "Group Health Check"},
-		Item{ItemTitleLevel2, "Ping [/ping]"},
-		Item{ItemTitleLevel3, "Ping-Pong [GET]"},
-		Item{ItemOverview, req},
+		{Type: ItemTitleLevel1, Value: "DS API"},
+		{Type: ItemDataStructures, Value: "Data Structures"},
+		{Type: ItemModel, Value: "Produce"},
+		{Type: ItemPropertyName, Value: "colour"},
+		{Type: ItemPropertyType, Value: "string"},
+		{Type: ItemPropertyName, Value: "dimensions"},
+		{Type: ItemPropertyType, Value: "object"},
+		{Type: ItemIndent, Value: ""},
+		{Type: ItemPropertyName, Value: "radius"},
+		{Type: ItemPropertyType, Value: "number"},
+		{Type: ItemPropertyName, Value: "core"},
+		{Type: ItemPropertyType, Value: "object"},
+		{Type: ItemIndent, Value: ""},
+		{Type: ItemPropertyName, Value: "size"},
+		{Type: ItemPropertyType, Value: "number"},
+		{Type: ItemDedent, Value: ""},
+		{Type: ItemDedent, Value: ""},
+		{Type: ItemPropertyName, Value: "fruit"},
+		{Type: ItemPropertyType, Value: "boolean"},
+		{Type: ItemModel, Value: "Dimension"},
+		{Type: ItemPropertyName, Value: "radius"},
+		{Type: ItemPropertyType, Value: "number"},
 	}
 
 	for i, ex := range expected {
 		item := <-l.Items
-		if item != ex {
-			t.Errorf("[%v] item = %v, want %v: %s", i, item, ex, string(l.Peek()))
+		if typeValue(item) != ex {
+			t.Errorf("[%v] item = %v, want %v", i, item, ex)
+		}
+	}
+}
+
+func Test_LexEscapedName(t *testing.T) {
+	t.Parallel()
+
+	// doc, pos, item, value
+	dataTable := [][]interface{}{
+		{"`first name` ", 13, ItemPropertyName, "first name"},
+		{"`Include`:", 10, ItemPropertyName, "Include"},
+		{"`first name\n", 11, ItemError, "missing closing backtick in property name"},
+	}
+
+	for i, td := range dataTable {
+		item, pos := lexItem(td[0].(string), LexEscapedName)
+
+		expPos := td[1].(int)
+		if expPos != pos {
+			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
+		}
+
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
+			t.Errorf("[%v] item = %v, want %v", i, item, expected)
+		}
+	}
+}
+
+func Test_LexInclude(t *testing.T) {
+	t.Parallel()
+
+	// doc, pos, item, value
+	dataTable := [][]interface{}{
+		{" Timestamps\n", 12, ItemInclude, "Timestamps"},
+		{"  Audit-Log\n+", 12, ItemInclude, "Audit-Log"},
+	}
+
+	for i, td := range dataTable {
+		item, pos := lexItem(td[0].(string), LexInclude)
+
+		expPos := td[1].(int)
+		if expPos != pos {
+			t.Errorf("[%v] pos = %v, want %v", i, pos, expPos)
+		}
+
+		expected := Item{Type: td[2].(ItemType), Value: td[3].(string)}
+		if typeValue(item) != expected {
+			t.Errorf("[%v] item = %v, want %v", i, item, expected)
+		}
+	}
+}
+
+func Test_include_apib_document(t *testing.T) {
+	t.Parallel()
+	var doc = `## Data Structures
+
+### Produce
++ Include Timestamps
++ Include (string)
++ Include`
+
+	l := New("meta.apib", doc)
+
+	go func() {
+		l.Run()
+	}()
+
+	expected := []Item{
+		{Type: ItemDataStructures, Value: "Data Structures"},
+		{Type: ItemModel, Value: "Produce"},
+		{Type: ItemInclude, Value: "Timestamps"},
+		{Type: ItemPropertyName, Value: "Include"},
+		{Type: ItemPropertyType, Value: "string"},
+		{Type: ItemPropertyName, Value: "Include"},
+	}
+
+	for i, ex := range expected {
+		item := <-l.Items
+		if typeValue(item) != ex {
+			t.Errorf("[%v] item = %v, want %v", i, item, ex)
+		}
+	}
+}
+
+func Test_one_of_apib_document(t *testing.T) {
+	t.Parallel()
+	var doc = `## Data Structures
+
+### Payment
++ One Of
+    + card (Card)
+    + Properties
+        + iban (string)
+
+### Method (enum)
++ (Card)`
+
+	l := New("meta.apib", doc)
+
+	go func() {
+		l.Run()
+	}()
+
+	expected := []Item{
+		{Type: ItemDataStructures, Value: "Data Structures"},
+		{Type: ItemModel, Value: "Payment"},
+		{Type: ItemOneOf, Value: "One Of"},
+		{Type: ItemIndent, Value: ""},
+		{Type: ItemPropertyName, Value: "card"},
+		{Type: ItemPropertyType, Value: "Card"},
+		{Type: ItemTypeSection, Value: "Properties"},
+		{Type: ItemIndent, Value: ""},
+		{Type: ItemPropertyName, Value: "iban"},
+		{Type: ItemPropertyType, Value: "string"},
+		{Type: ItemModel, Value: "Method"},
+		{Type: ItemModelType, Value: "enum"},
+		{Type: ItemPropertyName, Value: ""},
+		{Type: ItemPropertyType, Value: "Card"},
+	}
+
+	for i, ex := range expected {
+		item := <-l.Items
+		if typeValue(item) != ex {
+			t.Errorf("[%v] item = %v, want %v", i, item, ex)
 		}
 	}
 }
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// TypeScriptWriter writes the data structures of a Document as TypeScript
+// declarations named like the types of GoWriter.
+type TypeScriptWriter struct {
+	io.Writer
+
+	// doc is the document being written, used to expand includes.
+	doc *Document
+	buf bytes.Buffer
+}
+
+func NewTypeScriptWriter(w io.Writer) *TypeScriptWriter {
+	return &TypeScriptWriter{Writer: w}
+}
+
+// WriteDoc writes each data structure of doc as an exported interface or,
+// for enums, arrays, primitives and One Ofs, an exported type alias.
+func (w *TypeScriptWriter) WriteDoc(doc *Document) error {
+	w.doc = doc
+	w.buf.Reset()
+
+	if doc.Title != "" {
+		fmt.Fprintf(&w.buf, "// Generated from the %v blueprint.\n\n", doc.Title)
+	}
+
+	for _, model := range doc.DataStructures {
+		name := GoName(model.Name)
+		w.writeComment("", model.Description)
+
+		switch {
+		case model.IsTypeEnum():
+			fmt.Fprintf(&w.buf, "export type %v = %v;\n\n", name, typeUnion(model.Properties))
+
+		case model.IsEnum:
+			w.writeEnum(name, model.Type, model.Properties)
+
+		case model.IsArray:
+			fmt.Fprintf(&w.buf, "export type %v = %v;\n\n", name, w.tsType(name, &Property{Type: model.Type, IsArray: true}))
+
+		case isPrimitive(model.Type):
+			fmt.Fprintf(&w.buf, "export type %v = %v;\n\n", name, model.Type)
+
+		default:
+			w.writeInterface(name, model.Base(), model.Properties)
+		}
+	}
+
+	_, err := w.Write(bytes.TrimSuffix(w.buf.Bytes(), []byte("\n")))
+	return err
+}
+
+// writeComment writes text as a TSDoc comment at indent.
+func (w *TypeScriptWriter) writeComment(indent, text string) {
+	if text == "" {
+		return
+	}
+
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		fmt.Fprintf(&w.buf, "%v/** %v */\n", indent, strings.TrimSpace(text))
+		return
+	}
+
+	fmt.Fprintf(&w.buf, "%v/**\n", indent)
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t\r")
+		if line == "" {
+			fmt.Fprintf(&w.buf, "%v *\n", indent)
+			continue
+		}
+		fmt.Fprintf(&w.buf, "%v * %v\n", indent, line)
+	}
+	fmt.Fprintf(&w.buf, "%v */\n", indent)
+}
+
+// writeInterface writes the interface name followed by the types synthesised
+// for its inline properties. An object with One Of sections or a base that is
+// a type alias is written as the intersection of the base, its members and a
+// union of the alternatives, an interface cannot extend a union.
+func (w *TypeScriptWriter) writeInterface(name, base string, properties []*Property) {
+	properties = w.doc.Expand(properties)
+
+	var members, oneOfs []*Property
+	for _, property := range properties {
+		if property.OneOf {
+			oneOfs = append(oneOfs, property)
+			continue
+		}
+		members = append(members, property)
+	}
+
+	if len(oneOfs) == 0 && !w.isTypeAlias(base, map[string]bool{}) {
+		extends := ""
+		if base != "" {
+			extends = " extends " + GoName(base)
+		}
+		fmt.Fprintf(&w.buf, "export interface %v%v {\n", name, extends)
+		w.writeMembers(name, members)
+		fmt.Fprintf(&w.buf, "}\n\n")
+	} else {
+		fmt.Fprintf(&w.buf, "export type %v = ", name)
+		if base != "" {
+			fmt.Fprintf(&w.buf, "%v & ", GoName(base))
+		}
+		fmt.Fprintf(&w.buf, "{\n")
+		w.writeMembers(name, members)
+		fmt.Fprintf(&w.buf, "}")
+		for _, oneOf := range oneOfs {
+			var alts []string
+			for _, alt := range oneOf.Properties {
+				alts = append(alts, w.alternative(name, alt))
+			}
+			fmt.Fprintf(&w.buf, " & (%v)", strings.Join(alts, " | "))
+		}
+		fmt.Fprintf(&w.buf, ";\n\n")
+	}
+
+	for _, oneOf := range oneOfs {
+		for _, alt := range oneOf.Properties {
+			w.writeInlineTypes(name, w.doc.Expand(alternativeMembers(alt)))
+		}
+	}
+	w.writeInlineTypes(name, members)
+}
+
+// isTypeAlias reports whether the data structure name is written as a type
+// alias rather than an interface.
+func (w *TypeScriptWriter) isTypeAlias(name string, seen map[string]bool) bool {
+	ds := w.doc.Lookup(name)
+	if ds == nil || seen[ds.Name] {
+		return false
+	}
+	seen[ds.Name] = true
+
+	if ds.IsEnum || ds.IsArray || isPrimitive(ds.Type) {
+		return true
+	}
+	for _, property := range w.doc.Expand(ds.Properties) {
+		if property.OneOf {
+			return true
+		}
+	}
+	return w.isTypeAlias(ds.Base(), seen)
+}
+
+// writeMembers writes a property signature for each of properties.
+func (w *TypeScriptWriter) writeMembers(parent string, properties []*Property) {
+	for _, property := range properties {
+		w.writeComment("  ", property.Description)
+		fmt.Fprintf(&w.buf, "  %v: %v;\n", propertyKey(property), w.tsType(parent, property))
+	}
+}
+
+// writeInlineTypes writes the types synthesised for inline objects and enums
+// of properties, e.g. ProduceDimensions.
+func (w *TypeScriptWriter) writeInlineTypes(parent string, properties []*Property) {
+	for _, property := range properties {
+		switch {
+		case property.IsInline():
+			w.writeInterface(inlineName(parent, property), "", property.Properties)
+		case property.IsTypeEnum():
+			fmt.Fprintf(&w.buf, "export type %v = %v;\n\n", inlineName(parent, property), typeUnion(property.Properties))
+		case property.IsInlineEnum():
+			w.writeEnum(inlineName(parent, property), property.Type, property.Properties)
+		}
+	}
+}
+
+// alternative returns the type of an alternative of a One Of in parent, an
+// included type by name and other members as an object type literal.
+func (w *TypeScriptWriter) alternative(parent string, alt *Property) string {
+	members := alternativeMembers(alt)
+	if len(members) == 1 && members[0].Include != "" {
+		return GoName(members[0].Include)
+	}
+
+	var sigs []string
+	for _, property := range w.doc.Expand(members) {
+		sigs = append(sigs, fmt.Sprintf("%v: %v", propertyKey(property), w.tsType(parent, property)))
+	}
+	if len(sigs) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(sigs, "; ") + " }"
+}
+
+// writeEnum writes name as a union of the literal values of members, which
+// are strings unless t is number or boolean.
+func (w *TypeScriptWriter) writeEnum(name, t string, members []*Property) {
+	members = w.doc.Expand(members)
+
+	literals := make([]string, 0, len(members))
+	for _, member := range members {
+		b, _ := json.Marshal(primitiveValue(t, member.Name))
+		literals = append(literals, string(b))
+	}
+	if len(literals) == 0 {
+		literals = append(literals, "never")
+	}
+
+	fmt.Fprintf(&w.buf, "export type %v = %v;\n\n", name, strings.Join(literals, " | "))
+}
+
+// tsType returns the TypeScript type of property in the type parent.
+// Nullable properties also accept null.
+func (w *TypeScriptWriter) tsType(parent string, property *Property) string {
+	t := property.Type
+	switch {
+	case property.IsInline() || property.IsInlineEnum() || property.IsTypeEnum():
+		t = inlineName(parent, property)
+	case t == "" || t == "enum":
+		t = "string"
+	case t == "object":
+		t = "Record<string, unknown>"
+	case t == "array":
+		t = "unknown[]"
+	case !isPrimitive(t):
+		t = GoName(t)
+	}
+
+	if property.IsArray {
+		t += "[]"
+	}
+	if property.Nullable {
+		t += " | null"
+	}
+	return t
+}
+
+// typeUnion returns the union of the named types of an enum's members.
+func typeUnion(members []*Property) string {
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, GoName(member.Type))
+	}
+	return strings.Join(names, " | ")
+}
+
+var identifierRE = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// propertyKey returns the property name, quoted when it is not an
+// identifier, followed by ? when the property is optional.
+func propertyKey(property *Property) string {
+	key := property.Name
+	if !identifierRE.MatchString(key) {
+		b, _ := json.Marshal(key)
+		key = string(b)
+	}
+	if !property.Required {
+		key += "?"
+	}
+	return key
+}
@@ -0,0 +1,90 @@
+package main_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/nfisher/apib2go"
+)
+
+func Test_TypeScriptWriter_WriteDoc_should_write_declarations(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse("fruit.apib", `# Fruit API
+## Data Structures
+### Dimension
++ radius (number)
+
+### Produce (Dimension)
+Something to eat.
+
++ colour: yellow (string, required) - What colour is it?
++ seeds (array[object])
+    + weight (number)
++ content-type (string, nullable)
++ grade (enum[number])
+    + 1
+    + 2
+
+### Colour (enum[string])
++ red
++ green
+
+### Crate (array[Produce])
+
+### Delivery
++ id (string, required)
++ One Of
+    + address (string)
+    + Properties
+        + Include Dimension
+
+### Rush (Delivery)
++ priority (number)`)
+	if err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	if err := NewTypeScriptWriter(&buf).WriteDoc(doc); err != nil {
+		t.Fatalf("err = %v, want nil", err)
+	}
+
+	expected := `// Generated from the Fruit API blueprint.
+
+export interface Dimension {
+  radius?: number;
+}
+
+/** Something to eat. */
+export interface Produce extends Dimension {
+  /** What colour is it? */
+  colour: string;
+  seeds?: ProduceSeeds[];
+  "content-type"?: string | null;
+  grade?: ProduceGrade;
+}
+
+export interface ProduceSeeds {
+  weight?: number;
+}
+
+export type ProduceGrade = 1 | 2;
+
+export type Colour = "red" | "green";
+
+export type Crate = Produce[];
+
+export type Delivery = {
+  id: string;
+} & ({ address?: string } | Dimension);
+
+export type Rush = Delivery & {
+  priority?: number;
+};
+`
+
+	if buf.String() != expected {
+		t.Errorf("WriteDoc() =\n%v\nwant:\n%v", buf.String(), expected)
+	}
+}